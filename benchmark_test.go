@@ -109,3 +109,69 @@ func baselineDecode(r *http.Request) (*BenchReq, error) {
 
 	return &req, nil
 }
+
+type BenchLargeReq struct {
+	Items []BenchItem `body:"application/json"`
+}
+
+type BenchItem struct {
+	ID    int      `json:"id"`
+	State string   `json:"state"`
+	Tags  []string `json:"tags"`
+}
+
+func BenchmarkDecodeLargeBody(b *testing.B) {
+	body := largeBody()
+	r := largeBodyReq(body)
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var req BenchLargeReq
+		err := Decode(r, &req)
+		if err != nil {
+			b.Fatal("failed to decode", err.Error())
+		}
+		if len(req.Items) != 10000 {
+			b.Errorf("Decode(r, &req) items = %d, want %d", len(req.Items), 10000)
+		}
+		// reset body
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+}
+
+func BenchmarkBaselineLargeBody(b *testing.B) {
+	body := largeBody()
+	r := largeBodyReq(body)
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var req BenchLargeReq
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			b.Fatal("failed to read", err.Error())
+		}
+		if err := json.Unmarshal(buf, &req.Items); err != nil {
+			b.Fatal("failed to decode", err.Error())
+		}
+		if len(req.Items) != 10000 {
+			b.Errorf("json.Unmarshal(b, &req.Items) items = %d, want %d", len(req.Items), 10000)
+		}
+		// reset body
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+}
+
+func largeBody() []byte {
+	items := make([]BenchItem, 10000)
+	for i := range items {
+		items[i] = BenchItem{ID: i, State: "active", Tags: []string{"bob", "steve"}}
+	}
+	body, _ := json.Marshal(items)
+	return body
+}
+
+func largeBodyReq(body []byte) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(body)).WithContext(context.TODO())
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
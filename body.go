@@ -0,0 +1,83 @@
+package request
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+var (
+	readerType     = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	readCloserType = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+)
+
+// isRawType reports whether the type is assigned the request body as it is, a string or byte slice
+func isRawType(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+// streamBody is a request body streamed by the caller once decoded
+type streamBody struct {
+	io.Reader
+	close func()
+}
+
+func (s *streamBody) Close() error {
+	s.close()
+	return nil
+}
+
+// bufferedBody is a request body held in memory so it can be read again
+type bufferedBody struct {
+	*bytes.Reader
+	b []byte
+}
+
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+// setBufferedBody replaces the request body with the buffered bytes, and allows the body to be read again with GetBody
+func setBufferedBody(r *http.Request, b []byte) {
+	r.Body = &bufferedBody{Reader: bytes.NewReader(b), b: b}
+	r.ContentLength = int64(len(b))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return &bufferedBody{Reader: bytes.NewReader(b), b: b}, nil
+	}
+}
+
+// bufferBody reads the request body into memory, limited to the maximum size, so it can be read again
+func (d *Decoder) bufferBody(r *http.Request) error {
+	if r.Body == nil {
+		return nil
+	}
+	if _, ok := r.Body.(*bufferedBody); ok {
+		return nil
+	}
+	defer r.Body.Close()
+
+	var body io.Reader = r.Body
+	if d.maxBodyBytes > 0 {
+		if r.ContentLength > d.maxBodyBytes {
+			return ErrBodyTooLarge
+		}
+		body = http.MaxBytesReader(nil, r.Body, d.maxBodyBytes)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return bodyError(err)
+	}
+	setBufferedBody(r, b)
+	return nil
+}
+
+// hasTaggedBody reports whether the struct type has a field assigned from the request body
+func hasTaggedBody(t reflect.Type) bool {
+	return typeInfo(t).taggedBody
+}
+
+// hasRawBody reports whether the struct type has a field assigned the raw request body
+func hasRawBody(t reflect.Type) bool {
+	return typeInfo(t).rawBody
+}
@@ -0,0 +1,219 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_restoreBody(t *testing.T) {
+	body := `{"state":"idle"}`
+	r := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(body)))
+	r.Header.Set("Content-Type", "application/json")
+	var req struct {
+		State string `json:"state"`
+	}
+	if err := NewDecoder(WithRestoreBody()).Decode(r, &req); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	if req.State != "idle" {
+		t.Errorf("Decoder.Decode() state = %v, want idle", req.State)
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil || string(b) != body {
+		t.Errorf("r.Body = %s, %v, want %s", b, err, body)
+	}
+	if r.ContentLength != int64(len(body)) {
+		t.Errorf("r.ContentLength = %d, want %d", r.ContentLength, len(body))
+	}
+	rc, err := r.GetBody()
+	if err != nil {
+		t.Fatalf("r.GetBody() error = %v", err)
+	}
+	if b, _ := io.ReadAll(rc); string(b) != body {
+		t.Errorf("r.GetBody() = %s, want %s", b, body)
+	}
+}
+
+func TestDecoder_rawBody(t *testing.T) {
+	type event struct {
+		State string `json:"state"`
+	}
+	tests := []struct {
+		name     string
+		decoder  *Decoder
+		body     func(t *testing.T) []byte
+		encoding string
+		wantRaw  string
+		wantErr  error
+	}{
+		{
+			name:    "raw",
+			decoder: NewDecoder(),
+			body:    func(t *testing.T) []byte { return []byte(`{"state":"idle"}`) },
+			wantRaw: `{"state":"idle"}`,
+		},
+		{
+			name:     "decompressed",
+			decoder:  NewDecoder(),
+			body:     func(t *testing.T) []byte { return gzipped(t, `{"state":"idle"}`) },
+			encoding: "gzip",
+			wantRaw:  `{"state":"idle"}`,
+		},
+		{
+			name:    "too large",
+			decoder: NewDecoder(WithMaxBodyBytes(4)),
+			body:    func(t *testing.T) []byte { return []byte(`{"state":"idle"}`) },
+			wantErr: ErrBodyTooLarge,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body(t)))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Content-Encoding", tt.encoding)
+			var req struct {
+				Signature string          `header:"X-Signature"`
+				Raw       json.RawMessage `body:"raw"`
+				Event     event           `body:"application/json"`
+			}
+			err := tt.decoder.Decode(r, &req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decoder.Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if string(req.Raw) != tt.wantRaw {
+				t.Errorf("Decoder.Decode() raw = %s, want %s", req.Raw, tt.wantRaw)
+			}
+			if req.Event.State != "idle" {
+				t.Errorf("Decoder.Decode() state = %v, want idle", req.Event.State)
+			}
+		})
+	}
+}
+
+func TestDecoder_rawBodyUnsupported(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	var req struct {
+		Raw int `body:"raw"`
+	}
+	if err := Decode(r, &req); err == nil {
+		t.Errorf("Decode() error = nil, want unsupported raw body type")
+	}
+}
+
+func TestDecoder_textBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		encoding    string
+	}{
+		{name: "text", body: []byte("hello world"), contentType: "text/plain"},
+		{name: "binary", body: []byte{0x00, 0xff, 0x10}, contentType: "application/octet-stream"},
+		{name: "empty", body: nil, contentType: "text/plain"},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			newRequest := func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+				r.Header.Set("Content-Type", tt.contentType)
+				return r
+			}
+
+			var text struct {
+				Body string `body:"text/plain"`
+			}
+			if err := Decode(newRequest(), &text); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if text.Body != string(tt.body) {
+				t.Errorf("Decode() string = %q, want %q", text.Body, tt.body)
+			}
+
+			var binary struct {
+				Body []byte `body:"application/octet-stream"`
+			}
+			if err := Decode(newRequest(), &binary); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !bytes.Equal(binary.Body, tt.body) {
+				t.Errorf("Decode() []byte = %v, want %v", binary.Body, tt.body)
+			}
+
+			var reader struct {
+				Body io.Reader `body:"application/octet-stream"`
+			}
+			if err := Decode(newRequest(), &reader); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if b, err := io.ReadAll(reader.Body); err != nil || !bytes.Equal(b, tt.body) {
+				t.Errorf("Decode() io.Reader = %v, %v, want %v", b, err, tt.body)
+			}
+
+			var readCloser struct {
+				Body io.ReadCloser `body:"application/octet-stream,required"`
+			}
+			err := Decode(newRequest(), &readCloser)
+			if tt.body == nil {
+				if !errors.Is(err, ErrMissing) {
+					t.Errorf("Decode() error = %v, want %v", err, ErrMissing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			defer readCloser.Body.Close()
+			if b, err := io.ReadAll(readCloser.Body); err != nil || !bytes.Equal(b, tt.body) {
+				t.Errorf("Decode() io.ReadCloser = %v, %v, want %v", b, err, tt.body)
+			}
+		})
+	}
+}
+
+func TestDecoder_streamBody(t *testing.T) {
+	body := &trackingBody{Reader: strings.NewReader(strings.Repeat("a", 1<<16))}
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	var req struct {
+		Upload io.ReadCloser `body:"application/octet-stream"`
+	}
+	if err := Decode(r, &req); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if body.read >= 1<<16 || body.closed {
+		t.Errorf("Decode() read %d bytes closed %t, want body streamed", body.read, body.closed)
+	}
+	n, _ := io.Copy(io.Discard, req.Upload)
+	req.Upload.Close()
+	if n != 1<<16 || !body.closed {
+		t.Errorf("Upload read %d bytes closed %t, want %d bytes closed", n, body.closed, 1<<16)
+	}
+}
+
+type trackingBody struct {
+	io.Reader
+	read   int
+	closed bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
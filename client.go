@@ -0,0 +1,59 @@
+package request
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// Client sends HTTP requests encoded from tagged structs, and decodes the responses into tagged structs
+type Client struct {
+	// BaseURL is prefixed to the path template of each request, i.e. https://api.example.com
+	BaseURL string
+	// HTTPClient sends the requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// Decoder decodes the responses, the package level decoder if nil
+	Decoder *Decoder
+}
+
+// Do sends a request encoded from in, the same as Encode, to the path template on the client's base URL,
+// and decodes the response headers and body into Resp using its field tags.
+// Responses with a status code other than 2xx are returned as a *ResponseError,
+// and 204 No Content responses are returned as a nil Resp when it is a pointer, the same as Handler answers nil responses.
+func Do[Req, Resp any](ctx context.Context, c *Client, method, pathTemplate string, in Req) (Resp, error) {
+	var out Resp
+	r, err := Encode(method, c.BaseURL+pathTemplate, in)
+	if err != nil {
+		return out, err
+	}
+	r = r.WithContext(ctx)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(r)
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return out, &ResponseError{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+	}
+
+	if resp.StatusCode == http.StatusNoContent && reflect.TypeOf(&out).Elem().Kind() == reflect.Pointer {
+		return out, nil
+	}
+
+	decoder := c.Decoder
+	if decoder == nil {
+		decoder = defaultDecoder
+	}
+	if err := decoder.DecodeResponse(resp, &out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
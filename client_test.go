@@ -0,0 +1,90 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type clientUserRequest struct {
+	ID    string `path:"id"`
+	Token string `header:"Authorization"`
+	State string `json:"state"`
+}
+
+type clientUserResponse struct {
+	ETag  string `header:"ETag"`
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
+
+func TestDo(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var req clientUserRequest
+		if err := Decode(r, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.Token != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": req.ID, "state": req.State})
+	}).Methods(http.MethodPut)
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+	t.Run("decodes response", func(t *testing.T) {
+		got, err := Do[clientUserRequest, clientUserResponse](context.Background(), client, http.MethodPut, "/users/{id}",
+			clientUserRequest{ID: "1", Token: "Bearer token", State: "active"})
+		if err != nil {
+			t.Fatalf("Do() unexpected error: %v", err)
+		}
+		want := clientUserResponse{ETag: `"v1"`, ID: "1", State: "active"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Do() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		got, err := Do[clientUserRequest, *clientUserResponse](context.Background(), client, http.MethodDelete, "/users/{id}",
+			clientUserRequest{ID: "1"})
+		if err != nil || got != nil {
+			t.Errorf("Do() = %v, %v, want nil response", got, err)
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		_, err := Do[clientUserRequest, clientUserResponse](context.Background(), client, http.MethodPut, "/users/{id}",
+			clientUserRequest{ID: "1"})
+		var respErr *ResponseError
+		if !errors.As(err, &respErr) {
+			t.Fatalf("Do() error = %v, want *ResponseError", err)
+		}
+		if respErr.StatusCode != http.StatusUnauthorized || string(respErr.Body) != `{"error":"unauthorized"}` {
+			t.Errorf("Do() error = %v, body %s", respErr, respErr.Body)
+		}
+	})
+
+	t.Run("encode failure", func(t *testing.T) {
+		_, err := Do[clientUserRequest, clientUserResponse](context.Background(), client, http.MethodPut, "/users/{userID}",
+			clientUserRequest{ID: "1"})
+		if !errors.Is(err, ErrMissing) {
+			t.Errorf("Do() error = %v, want %v", err, ErrMissing)
+		}
+	})
+}
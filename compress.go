@@ -0,0 +1,75 @@
+package request
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxDecompressedBytes limits decompressed request bodies when neither WithMaxDecompressedBytes nor WithMaxBodyBytes is set
+const DefaultMaxDecompressedBytes = 10 << 20
+
+// Decompressor creates a reader that decompresses a request body encoded with a content encoding
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// defaultDecompressors decode the content encodings supported by the standard library
+func defaultDecompressors() map[string]Decompressor {
+	gzipDecompressor := func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	}
+	return map[string]Decompressor{
+		"gzip":    gzipDecompressor,
+		"x-gzip":  gzipDecompressor,
+		"deflate": zlib.NewReader,
+	}
+}
+
+// decompress wraps the body with the decompressors for each content encoding in the order they were applied.
+// The decompressed body is limited to the maximum decompressed size, or the maximum body size when it is not set,
+// or DefaultMaxDecompressedBytes when neither is set. A negative maximum decompressed size removes the limit.
+// The returned func closes the decompressors.
+func (d *Decoder) decompress(body io.Reader, contentEncoding string) (io.Reader, func(), error) {
+	var closers []io.Closer
+	closeAll := func() {
+		for _, c := range closers {
+			c.Close()
+		}
+	}
+
+	encodings := strings.Split(contentEncoding, ",")
+	decompressed := false
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		decompressor, ok := d.decompressors[encoding]
+		if !ok {
+			closeAll()
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+		}
+		rc, err := decompressor(body)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, rc)
+		body = rc
+		decompressed = true
+	}
+
+	limit := d.maxDecompressedBytes
+	if limit == 0 {
+		limit = d.maxBodyBytes
+	}
+	if limit == 0 {
+		limit = DefaultMaxDecompressedBytes
+	}
+	if decompressed && limit > 0 {
+		body = http.MaxBytesReader(nil, io.NopCloser(body), limit)
+	}
+	return body, closeAll, nil
+}
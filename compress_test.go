@@ -0,0 +1,162 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func deflated(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecoder_contentEncoding(t *testing.T) {
+	base64Decompressor := func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+	}
+	tests := []struct {
+		name     string
+		decoder  *Decoder
+		encoding string
+		body     func(t *testing.T) []byte
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "identity",
+			decoder:  NewDecoder(),
+			encoding: "identity",
+			body:     func(t *testing.T) []byte { return []byte(`{"state":"idle"}`) },
+			want:     "idle",
+		},
+		{
+			name:     "gzip",
+			decoder:  NewDecoder(),
+			encoding: "gzip",
+			body:     func(t *testing.T) []byte { return gzipped(t, `{"state":"idle"}`) },
+			want:     "idle",
+		},
+		{
+			name:     "deflate",
+			decoder:  NewDecoder(),
+			encoding: "deflate",
+			body:     func(t *testing.T) []byte { return deflated(t, []byte(`{"state":"idle"}`)) },
+			want:     "idle",
+		},
+		{
+			name:     "multiple encodings",
+			decoder:  NewDecoder(),
+			encoding: "gzip, deflate",
+			body:     func(t *testing.T) []byte { return deflated(t, gzipped(t, `{"state":"idle"}`)) },
+			want:     "idle",
+		},
+		{
+			name:     "custom decompressor",
+			decoder:  NewDecoder(WithDecompressor("BASE64", base64Decompressor)),
+			encoding: "base64",
+			body: func(t *testing.T) []byte {
+				return []byte(base64.StdEncoding.EncodeToString([]byte(`{"state":"idle"}`)))
+			},
+			want: "idle",
+		},
+		{
+			name:     "empty body",
+			decoder:  NewDecoder(),
+			encoding: "gzip",
+			body:     func(t *testing.T) []byte { return nil },
+		},
+		{
+			name:     "unsupported encoding",
+			decoder:  NewDecoder(),
+			encoding: "br",
+			body:     func(t *testing.T) []byte { return []byte(`{"state":"idle"}`) },
+			wantErr:  ErrUnsupportedEncoding,
+		},
+		{
+			name:     "decompressed too large",
+			decoder:  NewDecoder(WithMaxBodyBytes(1024), WithMaxDecompressedBytes(1024)),
+			encoding: "gzip",
+			body: func(t *testing.T) []byte {
+				return gzipped(t, `{"state":"`+strings.Repeat("a", 1<<20)+`"}`)
+			},
+			wantErr: ErrBodyTooLarge,
+		},
+		{
+			name:     "decompressed larger than max body",
+			decoder:  NewDecoder(WithMaxBodyBytes(1 << 20)),
+			encoding: "gzip",
+			body: func(t *testing.T) []byte {
+				return gzipped(t, `{"state":"`+strings.Repeat("a", 50<<20)+`"}`)
+			},
+			wantErr: ErrBodyTooLarge,
+		},
+		{
+			name:     "decompressed larger than default",
+			decoder:  defaultDecoder,
+			encoding: "gzip",
+			body: func(t *testing.T) []byte {
+				return gzipped(t, `{"state":"`+strings.Repeat("a", 50<<20)+`"}`)
+			},
+			wantErr: ErrBodyTooLarge,
+		},
+		{
+			name:     "decompressed without limit",
+			decoder:  NewDecoder(WithMaxDecompressedBytes(-1)),
+			encoding: "gzip",
+			body: func(t *testing.T) []byte {
+				return gzipped(t, `{"state":"`+strings.Repeat("a", DefaultMaxDecompressedBytes)+`"}`)
+			},
+			want: strings.Repeat("a", DefaultMaxDecompressedBytes),
+		},
+		{
+			name:     "decompressed within max body",
+			decoder:  NewDecoder(WithMaxBodyBytes(1024)),
+			encoding: "gzip",
+			body:     func(t *testing.T) []byte { return gzipped(t, `{"state":"idle"}`) },
+			want:     "idle",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body(t)))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Content-Encoding", tt.encoding)
+			var req struct {
+				State string `json:"state"`
+			}
+			err := tt.decoder.Decode(r, &req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decoder.Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if req.State != tt.want {
+				t.Errorf("Decoder.Decode() state = %v, want %v", req.State, tt.want)
+			}
+		})
+	}
+}
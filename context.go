@@ -0,0 +1,69 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// WithContextKey registers the context key of the values assigned by ctx tags with the name.
+// Fields with a ctx tag name without a registered key fail to decode.
+func WithContextKey(name string, key interface{}) Option {
+	return func(d *Decoder) {
+		d.contextKeys[name] = key
+	}
+}
+
+// decodeContext assigns the context value of the key registered with the name to the field
+func (d *Decoder) decodeContext(field reflect.Value, typ reflect.Type, ctx context.Context, name string) (bool, error) {
+	key, ok := d.contextKeys[name]
+	if !ok {
+		return false, fmt.Errorf("unknown context key: %s", name)
+	}
+	v := ctx.Value(key)
+	if v == nil {
+		return false, nil
+	}
+	return true, resolveInterface(field, typ, v)
+}
+
+// trustedTags are the source tags of values the request body must not assign
+var trustedTags = []string{"ctx", "request"}
+
+// trustedFields are the fields assigned by trusted tags, and their values
+type trustedFields []trustedField
+
+type trustedField struct {
+	field reflect.Value
+	value reflect.Value
+}
+
+// add records the value of a field with a trusted tag
+func (t *trustedFields) add(field reflect.Value) {
+	if t == nil {
+		return
+	}
+	value := reflect.New(field.Type()).Elem()
+	value.Set(field)
+	*t = append(*t, trustedField{field: field, value: value})
+}
+
+// hide clears the trusted fields while the body is decoded, so the body cannot assign them or the values they point to
+func (t *trustedFields) hide() {
+	if t == nil {
+		return
+	}
+	for _, f := range *t {
+		f.field.Set(reflect.Zero(f.field.Type()))
+	}
+}
+
+// restore assigns the trusted fields their values once the body is decoded
+func (t *trustedFields) restore() {
+	if t == nil {
+		return
+	}
+	for _, f := range *t {
+		f.field.Set(f.value)
+	}
+}
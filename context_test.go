@@ -0,0 +1,194 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type claimsKey struct{}
+
+type ctxKey string
+
+type claims struct {
+	Subject string
+	Roles   []string
+}
+
+type tenantID string
+
+func (t tenantID) String() string { return string(t) }
+
+func TestDecoder_ctx(t *testing.T) {
+	decoder := NewDecoder(
+		WithContextKey("claims", claimsKey{}),
+		WithContextKey("tenant", ctxKey("tenant")),
+		WithContextKey("tenantNum", ctxKey("tenantNum")),
+		WithContextKey("missing", ctxKey("missing")),
+		WithContextKey("userID", ctxKey("userID")),
+		WithContextKey("addr", ctxKey("addr")),
+		WithContextKey("roles", ctxKey("roles")),
+	)
+	type request struct {
+		Claims     claims           `ctx:"claims"`
+		ClaimsPtr  *claims          `ctx:"claims"`
+		ClaimsOpt  Optional[claims] `ctx:"claims"`
+		Tenant     string           `ctx:"tenant"`
+		TenantNum  int              `ctx:"tenantNum"`
+		UserID     int64            `ctx:"userID"`
+		Addr       netip.Addr       `ctx:"addr"`
+		Missing    string           `ctx:"missing" default:"none"`
+		RoleFilter []string         `ctx:"roles"`
+	}
+	c := claims{Subject: "adam", Roles: []string{"admin"}}
+	addr := netip.MustParseAddr("10.0.0.1")
+
+	ctx := context.WithValue(context.Background(), claimsKey{}, &c)
+	ctx = context.WithValue(ctx, ctxKey("tenant"), tenantID("acme"))
+	ctx = context.WithValue(ctx, ctxKey("tenantNum"), tenantID("42"))
+	ctx = context.WithValue(ctx, ctxKey("userID"), 7)
+	ctx = context.WithValue(ctx, ctxKey("addr"), addr)
+	ctx = context.WithValue(ctx, ctxKey("roles"), "admin,owner")
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	var got request
+	if err := decoder.Decode(r, &got); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	want := request{
+		Claims:     c,
+		ClaimsPtr:  &c,
+		Tenant:     "acme",
+		TenantNum:  42,
+		UserID:     7,
+		Addr:       addr,
+		Missing:    "none",
+		RoleFilter: []string{"admin", "owner"},
+	}
+	want.ClaimsOpt.Set(c)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecoder_ctxErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		data  interface{}
+	}{
+		{
+			name:  "required",
+			value: nil,
+			data: &struct {
+				UserID string `ctx:"userID,required"`
+			}{},
+		},
+		{
+			name:  "invalid string",
+			value: "adam",
+			data: &struct {
+				UserID int `ctx:"userID"`
+			}{},
+		},
+		{
+			name:  "unassignable",
+			value: claims{Subject: "adam"},
+			data: &struct {
+				UserID int `ctx:"userID"`
+			}{},
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.value != nil {
+				ctx = context.WithValue(ctx, ctxKey("userID"), tt.value)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			err := NewDecoder(WithContextKey("userID", ctxKey("userID"))).Decode(r, tt.data)
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Source != "ctx" || fieldErr.Name != "userID" {
+				t.Errorf("Decode() error = %v, want ctx *FieldError", err)
+			}
+		})
+	}
+}
+
+func TestDecoder_ctxUnknownKey(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey("tenant"), "acme")
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	var req struct {
+		Tenant string `ctx:"tenant"`
+	}
+	err := NewDecoder().Decode(r, &req)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Source != "ctx" || fieldErr.Name != "tenant" {
+		t.Errorf("Decode() error = %v, want ctx *FieldError", err)
+	}
+}
+
+func TestDecoder_ctxBody(t *testing.T) {
+	decoder := NewDecoder(
+		WithContextKey("claims", claimsKey{}),
+		WithContextKey("tenant", ctxKey("tenant")),
+		WithContextKey("missing", ctxKey("missing")),
+	)
+	type item struct {
+		Tenant string `ctx:"tenant"`
+		Name   string `json:"name"`
+	}
+	type request struct {
+		Claims  *claims `ctx:"claims"`
+		Tenant  string  `ctx:"tenant"`
+		Missing string  `ctx:"missing"`
+		Name    string  `json:"name"`
+	}
+	type tagged struct {
+		Tenant string `ctx:"tenant"`
+		Item   item   `body:"application/json"`
+	}
+	tests := []struct {
+		name string
+		data interface{}
+		want interface{}
+	}{
+		{
+			name: "body",
+			data: &request{},
+			want: &request{Claims: &claims{Subject: "adam"}, Tenant: "good", Name: "adam"},
+		},
+		{
+			name: "body field",
+			data: &tagged{},
+			want: &tagged{Tenant: "good", Item: item{Tenant: "good", Name: "adam"}},
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			c := claims{Subject: "adam"}
+			ctx := context.WithValue(context.Background(), claimsKey{}, &c)
+			ctx = context.WithValue(ctx, ctxKey("tenant"), "good")
+			body := `{"Claims":{"Subject":"evil"},"Tenant":"evil","Missing":"evil","name":"adam"}`
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)).WithContext(ctx)
+			r.Header.Set("Content-Type", "application/json")
+
+			if err := decoder.Decode(r, tt.data); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.data, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", tt.data, tt.want)
+			}
+			if c.Subject != "adam" {
+				t.Errorf("Decode() assigned the context value from the body: %+v", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package request
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// csvColumn is a struct field assigned from a CSV column
+type csvColumn struct {
+	name     string
+	index    int
+	required bool
+}
+
+// decodeCSV decodes a CSV body with a header row into a slice of structs.
+// Columns are assigned to fields by their csv tag, or field name, and converted the same way as query values.
+func decodeCSV(body io.Reader, data interface{}) (bool, error) {
+	slice := reflect.ValueOf(data).Elem()
+	if slice.Kind() != reflect.Slice {
+		return false, fmt.Errorf("unsupported csv body type: %v", slice.Type())
+	}
+	elem := slice.Type().Elem()
+	ptr := elem.Kind() == reflect.Pointer
+	if ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return false, fmt.Errorf("unsupported csv body type: %v", slice.Type())
+	}
+
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return true, csvError(err)
+	}
+	columns, err := csvColumns(elem, header)
+	if err != nil {
+		return true, err
+	}
+
+	rows := reflect.MakeSlice(slice.Type(), 0, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return true, csvError(err)
+		}
+
+		row := reflect.New(elem).Elem()
+		for i, column := range columns {
+			if column.index < 0 {
+				continue
+			}
+			line, _ := reader.FieldPos(column.index)
+			cell := record[column.index]
+			if cell == "" {
+				if column.required {
+					return true, &LineError{Line: line, Column: column.name, Err: ErrMissing}
+				}
+				if row.Field(i).Kind() != reflect.String {
+					continue
+				}
+			}
+
+			field := row.Field(i)
+			if isSlice(field) {
+				err = resolveValues(field, field.Type(), strings.Split(cell, ","))
+			} else {
+				err = resolveValue(field, field.Type(), cell)
+			}
+			if err != nil {
+				return true, &LineError{Line: line, Column: column.name, Err: err}
+			}
+		}
+
+		if ptr {
+			row = row.Addr()
+		}
+		rows = reflect.Append(rows, row)
+	}
+	slice.Set(rows)
+	return true, nil
+}
+
+// csvColumns maps the struct fields to the index of their column in the header, indexed by field
+func csvColumns(t reflect.Type, header []string) ([]csvColumn, error) {
+	columns := make([]csvColumn, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts := parseTag(f.Tag.Get("csv"))
+		columns[i] = csvColumn{name: name, index: -1, required: opts.Contains("required")}
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			columns[i].name = f.Name
+		}
+		for j, h := range header {
+			if strings.TrimSpace(h) == columns[i].name {
+				columns[i].index = j
+				break
+			}
+		}
+		if columns[i].index < 0 && columns[i].required {
+			return nil, &LineError{Line: 1, Column: columns[i].name, Err: ErrMissing}
+		}
+	}
+	return columns, nil
+}
+
+// csvError reports CSV parse errors as line errors
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &LineError{Line: parseErr.Line, Err: parseErr.Err}
+	}
+	return bodyError(err)
+}
@@ -0,0 +1,119 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvRow struct {
+	Name    string        `csv:"name,required"`
+	Age     int           `csv:"age"`
+	Active  bool          `csv:"active"`
+	Timeout time.Duration `csv:"timeout"`
+	Tags    []string      `csv:"tags"`
+	Email   *string       `csv:"email"`
+	Ignored string        `csv:"-"`
+	Nick    string
+}
+
+func Test_decodeCSV(t *testing.T) {
+	email := "adam@example.com"
+	tests := []struct {
+		name       string
+		body       string
+		data       interface{}
+		want       interface{}
+		wantLine   int
+		wantColumn string
+		wantErr    bool
+	}{
+		{
+			name: "rows",
+			body: "name,age,active,timeout,tags,email,Nick,Ignored\nadam,30,true,5s,\"a,b\",adam@example.com,ad,x\neve,,false,1m,c,,,\n",
+			data: &[]csvRow{},
+			want: &[]csvRow{
+				{Name: "adam", Age: 30, Active: true, Timeout: 5 * time.Second, Tags: []string{"a", "b"}, Email: &email, Nick: "ad"},
+				{Name: "eve", Timeout: time.Minute, Tags: []string{"c"}},
+			},
+		},
+		{
+			name: "pointers",
+			body: "age,name\n30,adam\n",
+			data: &[]*csvRow{},
+			want: &[]*csvRow{{Name: "adam", Age: 30}},
+		},
+		{
+			name: "header only",
+			body: "name,age\n",
+			data: &[]csvRow{},
+			want: &[]csvRow{},
+		},
+		{
+			name:       "invalid cell",
+			body:       "name,age\nadam,30\neve,old\n",
+			data:       &[]csvRow{},
+			want:       &[]csvRow{},
+			wantLine:   3,
+			wantColumn: "age",
+		},
+		{
+			name:       "missing required cell",
+			body:       "name,age\n,30\n",
+			data:       &[]csvRow{},
+			want:       &[]csvRow{},
+			wantLine:   2,
+			wantColumn: "name",
+		},
+		{
+			name:       "missing required column",
+			body:       "age\n30\n",
+			data:       &[]csvRow{},
+			want:       &[]csvRow{},
+			wantLine:   1,
+			wantColumn: "name",
+		},
+		{
+			name:     "malformed",
+			body:     "name,age\nadam,30,extra\n",
+			data:     &[]csvRow{},
+			want:     &[]csvRow{},
+			wantLine: 2,
+		},
+		{
+			name:    "unsupported type",
+			body:    "name\nadam\n",
+			data:    &[]string{},
+			want:    &[]string{},
+			wantErr: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "text/csv; charset=utf-8")
+			err := Decode(r, tt.data)
+			var lineErr *LineError
+			switch {
+			case tt.wantErr:
+				if err == nil {
+					t.Errorf("Decode() error = nil, want error")
+				}
+			case tt.wantLine > 0:
+				if !errors.As(err, &lineErr) || lineErr.Line != tt.wantLine || lineErr.Column != tt.wantColumn {
+					t.Errorf("Decode() error = %v, want line %d column %q error", err, tt.wantLine, tt.wantColumn)
+				}
+			case err != nil:
+				t.Errorf("Decode() error = %v, want nil", err)
+			}
+			if !reflect.DeepEqual(tt.data, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", tt.data, tt.want)
+			}
+		})
+	}
+}
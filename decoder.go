@@ -0,0 +1,130 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// defaultDecoder decodes requests for the package level Decode func
+var defaultDecoder = NewDecoder()
+
+// Decoder decodes HTTP requests into structs, configured by options
+type Decoder struct {
+	maxBodyBytes         int64
+	maxDecompressedBytes int64
+	decompressors        map[string]Decompressor
+	discriminators       map[reflect.Type]discriminator
+	contextKeys          map[string]interface{}
+	errorHandler         ErrorHandler
+	strict               bool
+	useNumber            bool
+	restoreBody          bool
+	taggedBody           bool
+}
+
+// Option configures a Decoder
+type Option func(*Decoder)
+
+// NewDecoder creates a Decoder configured by the provided options
+func NewDecoder(opts ...Option) *Decoder {
+	d := &Decoder{
+		decompressors:  defaultDecompressors(),
+		discriminators: map[reflect.Type]discriminator{},
+		contextKeys:    map[string]interface{}{},
+		errorHandler:   WriteError,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// WithMaxBodyBytes limits the number of bytes read from the request body.
+// Requests with a larger Content-Length, or bodies that exceed the limit while reading, fail to decode with ErrBodyTooLarge.
+func WithMaxBodyBytes(n int64) Option {
+	return func(d *Decoder) {
+		d.maxBodyBytes = n
+	}
+}
+
+// WithMaxDecompressedBytes limits the number of bytes read from a request body once it is decompressed,
+// guarding against small compressed bodies that expand to a large size. Bodies that exceed the limit fail to decode with ErrBodyTooLarge.
+// Decompressed bodies are limited to the WithMaxBodyBytes limit when it is not set, or DefaultMaxDecompressedBytes when neither is set,
+// and a negative n removes the limit.
+func WithMaxDecompressedBytes(n int64) Option {
+	return func(d *Decoder) {
+		d.maxDecompressedBytes = n
+	}
+}
+
+// WithDecompressor decompresses request bodies with the Content-Encoding, replacing the default decompressor for the encoding.
+// The gzip and deflate encodings are supported by default, and request bodies with other encodings fail to decode with ErrUnsupportedEncoding.
+func WithDecompressor(encoding string, decompressor Decompressor) Option {
+	return func(d *Decoder) {
+		d.decompressors[strings.ToLower(encoding)] = decompressor
+	}
+}
+
+// WithStrict rejects JSON request bodies with fields the struct does not have, or data after the top-level value.
+// Strict decoding can also be enabled for a single field with the strict body tag option.
+func WithStrict() Option {
+	return func(d *Decoder) {
+		d.strict = true
+	}
+}
+
+// WithUseNumber decodes JSON numbers in the request body into interface{} values as json.Number instead of float64, preserving their precision.
+// Numbers can also be preserved for a single field with the useNumber body tag option.
+func WithUseNumber() Option {
+	return func(d *Decoder) {
+		d.useNumber = true
+	}
+}
+
+// WithRestoreBody buffers the request body so it can be read again once decoded, restoring the request's Body and GetBody
+// with the body as it was received.
+func WithRestoreBody() Option {
+	return func(d *Decoder) {
+		d.restoreBody = true
+	}
+}
+
+// WithErrorHandler writes the responses for requests that fail to decode in a Handler or Middleware, or for errors returned by a Handler,
+// replacing WriteError.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(d *Decoder) {
+		d.errorHandler = h
+	}
+}
+
+// Decode an HTTP request into the provided pointer.
+// Structs are decoded using their field tags, while other types are decoded from the request body.
+// Pointers to struct pointers are decoded into the struct, allocating it when the pointer is nil.
+func (d *Decoder) Decode(r *http.Request, data interface{}) error {
+	typ := reflect.TypeOf(data)
+	if typ == nil || (typ.Kind() == reflect.Ptr && reflect.ValueOf(data).IsNil()) {
+		return fmt.Errorf("invalid decode type: nil")
+	}
+	if typ.Kind() != reflect.Ptr {
+		return fmt.Errorf("invalid decode type: %v", typ.Kind())
+	}
+
+	for typ.Elem().Kind() == reflect.Ptr && structPointer(typ.Elem()) {
+		v := reflect.ValueOf(data).Elem()
+		if v.IsNil() {
+			v.Set(reflect.New(typ.Elem().Elem()))
+		}
+		data, typ = v.Interface(), typ.Elem()
+	}
+	return d.decodeRequest(r, typ.Elem(), data)
+}
+
+// structPointer reports whether the pointer type points to a struct, including through other pointers
+func structPointer(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
@@ -0,0 +1,229 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_maxBodyBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       *http.Request
+		wantErr error
+	}{
+		{
+			name: "within limit",
+			r:    httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"state":"idle"}`)),
+		},
+		{
+			name:    "content length exceeds limit",
+			r:       httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"state":"active","extra":"data"}`)),
+			wantErr: ErrBodyTooLarge,
+		},
+		{
+			name: "body exceeds limit",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(`{"state":"active","extra":"data"}`)))
+				r.ContentLength = -1
+				return r
+			}(),
+			wantErr: ErrBodyTooLarge,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			tt.r.Header.Set("Content-Type", "application/json")
+			var req struct {
+				State string `json:"state"`
+			}
+			err := NewDecoder(WithMaxBodyBytes(20)).Decode(tt.r, &req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decoder.Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecoder_strict(t *testing.T) {
+	type item struct {
+		State string `json:"state"`
+	}
+	type request struct {
+		Name  string            `json:"name"`
+		Item  item              `json:"item"`
+		Items []item            `json:"items"`
+		Extra map[string]string `json:"extra"`
+	}
+	type tagged struct {
+		Item item `body:"application/json,strict"`
+	}
+	type lenient struct {
+		Item item `body:"application/json"`
+	}
+	tests := []struct {
+		name      string
+		decoder   *Decoder
+		data      interface{}
+		body      string
+		wantErr   bool
+		wantField string
+		wantName  string
+	}{
+		{name: "lenient", decoder: NewDecoder(), data: &request{}, body: `{"name":"adam","stat":"idle"}`},
+		{name: "valid", decoder: NewDecoder(WithStrict()), data: &request{}, body: `{"name":"adam","item":{"state":"idle"},"extra":{"any":"key"}}`},
+		{name: "unknown field", decoder: NewDecoder(WithStrict()), data: &request{}, body: `{"name":"adam","stat":"idle"}`, wantErr: true, wantName: "/stat"},
+		{name: "unknown nested field", decoder: NewDecoder(WithStrict()), data: &request{}, body: `{"item":{"stat":"idle"}}`, wantErr: true, wantName: "/item/stat"},
+		{name: "unknown array field", decoder: NewDecoder(WithStrict()), data: &request{}, body: `{"items":[{"state":"idle"},{"stat":"idle"}]}`, wantErr: true, wantName: "/items/1/stat"},
+		{name: "trailing data", decoder: NewDecoder(WithStrict()), data: &request{}, body: `{"name":"adam"} {}`, wantErr: true},
+		{name: "lenient trailing data", decoder: NewDecoder(), data: &request{}, body: `{"name":"adam"} {}`, wantErr: true},
+		{name: "lenient trailing whitespace", decoder: NewDecoder(), data: &request{}, body: "{\"name\":\"adam\"} \r\n\t"},
+		{name: "tag lenient trailing data", decoder: NewDecoder(), data: &lenient{}, body: `{"state":"idle"} {}`, wantErr: true, wantField: "Item"},
+		{name: "tag unknown field", decoder: NewDecoder(), data: &tagged{}, body: `{"stat":"idle"}`, wantErr: true, wantField: "Item", wantName: "/stat"},
+		{name: "tag trailing data", decoder: NewDecoder(), data: &tagged{}, body: `{"state":"idle"} {}`, wantErr: true, wantField: "Item"},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			err := tt.decoder.Decode(r, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decoder.Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantName == "" && tt.wantField == "" {
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Decoder.Decode() error = %v, want field error", err)
+			}
+			if tt.wantName != "" && (fieldErr.Name != tt.wantName || !errors.Is(err, ErrUnknownField)) {
+				t.Errorf("Decoder.Decode() error name = %v, want unknown field %v", fieldErr.Name, tt.wantName)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("Decoder.Decode() error field = %v, want %v", fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestDecode_trailingData(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"x"} garbage`))
+	r.Header.Set("Content-Type", "application/json")
+	var data struct {
+		A string `json:"a"`
+	}
+	err := Decode(r, &data)
+	if err == nil || err.Error() != "invalid character 'g' after top-level value" {
+		t.Errorf("Decode() error = %v, want invalid character after top-level value", err)
+	}
+}
+
+func TestDecoder_useNumber(t *testing.T) {
+	type request struct {
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	type tagged struct {
+		Attributes map[string]interface{} `body:"application/json,useNumber"`
+	}
+	tests := []struct {
+		name    string
+		decoder *Decoder
+		data    interface{}
+		body    string
+		get     func(interface{}) interface{}
+		want    interface{}
+	}{
+		{
+			name:    "float64",
+			decoder: NewDecoder(),
+			data:    &request{},
+			body:    `{"attributes":{"id":9007199254740993}}`,
+			get:     func(data interface{}) interface{} { return data.(*request).Attributes["id"] },
+			want:    float64(9007199254740992),
+		},
+		{
+			name:    "decoder",
+			decoder: NewDecoder(WithUseNumber()),
+			data:    &request{},
+			body:    `{"attributes":{"id":9007199254740993}}`,
+			get:     func(data interface{}) interface{} { return data.(*request).Attributes["id"] },
+			want:    json.Number("9007199254740993"),
+		},
+		{
+			name:    "strict decoder",
+			decoder: NewDecoder(WithUseNumber(), WithStrict()),
+			data:    &request{},
+			body:    `{"attributes":{"id":9007199254740993}}`,
+			get:     func(data interface{}) interface{} { return data.(*request).Attributes["id"] },
+			want:    json.Number("9007199254740993"),
+		},
+		{
+			name:    "tag",
+			decoder: NewDecoder(),
+			data:    &tagged{},
+			body:    `{"id":9007199254740993}`,
+			get:     func(data interface{}) interface{} { return data.(*tagged).Attributes["id"] },
+			want:    json.Number("9007199254740993"),
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if err := tt.decoder.Decode(r, tt.data); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			if got := tt.get(tt.data); got != tt.want {
+				t.Errorf("Decoder.Decode() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	type item struct {
+		State string `json:"state" enum:"idle,active"`
+	}
+	var nilPtr *item
+	tests := []struct {
+		name    string
+		body    string
+		data    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "slice", body: `[{"state":"idle"},{"state":"active"}]`, data: &[]item{}, want: &[]item{{State: "idle"}, {State: "active"}}},
+		{name: "validated slice", body: `[{"state":"idle"},{"state":"gone"}]`, data: &[]item{}, want: &[]item{{State: "idle"}, {State: "gone"}}, wantErr: true},
+		{name: "map", body: `{"state":"idle"}`, data: &map[string]interface{}{}, want: &map[string]interface{}{"state": "idle"}},
+		{name: "string", body: `"idle"`, data: new(string), want: func() *string { s := "idle"; return &s }()},
+		{name: "int", body: `5`, data: new(int), want: func() *int { i := 5; return &i }()},
+		{name: "empty body", body: ``, data: &[]item{}, want: &[]item{}},
+		{name: "struct pointer", body: `{"state":"idle"}`, data: new(*item), want: func() **item { i := &item{State: "idle"}; return &i }()},
+		{name: "validated struct pointer", body: `{"state":"gone"}`, data: new(*item), want: func() **item { i := &item{State: "gone"}; return &i }(), wantErr: true},
+		{name: "nil", body: `{}`, data: nil, want: nil, wantErr: true},
+		{name: "nil pointer", body: `{}`, data: nilPtr, want: nilPtr, wantErr: true},
+		{name: "non pointer", body: `{}`, data: item{}, want: item{}, wantErr: true},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if err := NewDecoder().Decode(r, tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("Decoder.Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.data, tt.want) {
+				t.Errorf("Decoder.Decode() = %v, want %v", tt.data, tt.want)
+			}
+		})
+	}
+}
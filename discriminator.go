@@ -0,0 +1,94 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// discriminator maps the values of a JSON property to the concrete types implementing an interface
+type discriminator struct {
+	property string
+	mapping  map[string]reflect.Type
+}
+
+// WithDiscriminator decodes JSON request bodies into values of the interface type I, such as an OpenAPI oneOf,
+// choosing the concrete type from the mapping by the value of the discriminator property.
+// Mapping values are zero values of the concrete types, i.e. ClickEvent{} or &ClickEvent{} to decode pointers,
+// and the option panics for nil mapping values, which have no concrete type.
+// The concrete value is decoded using its field tags and validated once it is decoded.
+func WithDiscriminator[I any](property string, mapping map[string]I) Option {
+	return func(d *Decoder) {
+		disc := discriminator{property: property, mapping: map[string]reflect.Type{}}
+		for value, typ := range mapping {
+			t := reflect.TypeOf(typ)
+			if t == nil {
+				panic(fmt.Sprintf("request: nil discriminator mapping for %s %q", property, value))
+			}
+			disc.mapping[value] = t
+		}
+		d.discriminators[reflect.TypeOf((*I)(nil)).Elem()] = disc
+	}
+}
+
+// decodeDiscriminated decodes a JSON value into the concrete type chosen by its discriminator property, validates it, and assigns it to the interface
+func (d *Decoder) decodeDiscriminated(r *http.Request, body io.Reader, data interface{}, disc discriminator, opts tagOptions) (bool, error) {
+	var raw json.RawMessage
+	dec := json.NewDecoder(body)
+	if err := dec.Decode(&raw); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return true, err
+	}
+	if err := trailingData(dec, body); err != nil {
+		return true, err
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return true, err
+	}
+	property, ok := object[disc.property]
+	if !ok {
+		return true, &FieldError{Source: "body", Name: disc.property, Err: ErrMissing}
+	}
+	var value string
+	if err := json.Unmarshal(property, &value); err != nil {
+		value = string(property)
+	}
+	typ, ok := disc.mapping[value]
+	if !ok {
+		return true, &FieldError{Source: "body", Name: disc.property, Err: fmt.Errorf("unknown type: %s", value)}
+	}
+
+	elem := typ
+	if typ.Kind() == reflect.Pointer {
+		elem = typ.Elem()
+	}
+	concrete := reflect.New(elem)
+	absent := absentFields{}
+	trusted := trustedFields{}
+	if elem.Kind() == reflect.Struct {
+		if _, err := d.decodeStruct(r, elem, concrete.Interface(), "", absent, &trusted); err != nil {
+			return true, err
+		}
+	}
+	trusted.hide()
+	_, err := d.decodeJSON(bytes.NewReader(raw), concrete.Interface(), opts)
+	trusted.restore()
+	if err != nil {
+		return true, err
+	}
+	// the value is validated while it can be addressed, since the interface holds a copy of values that are not pointers
+	err = validateNested(r, concrete.Elem(), "", absent)
+
+	if typ.Kind() != reflect.Pointer {
+		concrete = concrete.Elem()
+	}
+	reflect.ValueOf(data).Elem().Set(concrete)
+	return true, err
+}
@@ -0,0 +1,175 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type event interface{}
+
+type clickEvent struct {
+	Type   string `json:"type"`
+	X      int    `json:"x" min:"0"`
+	Source string `header:"X-Source"`
+	Unit   string `query:"unit" enum:"cm,in"`
+}
+
+type keyEvent struct {
+	Type string `json:"type"`
+	Key  string `json:"key" minLen:"1"`
+	Unit string `query:"unit" enum:"cm,in"`
+}
+
+func TestDecoder_discriminator(t *testing.T) {
+	decoder := NewDecoder(
+		WithStrict(),
+		WithDiscriminator("type", map[string]event{
+			"click": clickEvent{},
+			"key":   &keyEvent{},
+		}),
+	)
+	type request struct {
+		Event event `body:"event"`
+	}
+	tests := []struct {
+		name      string
+		target    string
+		body      string
+		want      interface{}
+		wantName  string
+		wantErr   error
+		wantRule  string
+		wantField string
+	}{
+		{
+			name: "value",
+			body: `{"type":"click","x":4}`,
+			want: clickEvent{Type: "click", X: 4, Source: "web"},
+		},
+		{
+			name: "pointer",
+			body: `{"key":"enter","type":"key"}`,
+			want: &keyEvent{Type: "key", Key: "enter"},
+		},
+		{
+			name:   "value query",
+			target: "/?unit=cm",
+			body:   `{"type":"click","x":4}`,
+			want:   clickEvent{Type: "click", X: 4, Source: "web", Unit: "cm"},
+		},
+		{
+			name:      "invalid value query",
+			target:    "/?unit=mm",
+			body:      `{"type":"click","x":4}`,
+			wantField: "Event.Unit",
+			wantRule:  "enum",
+		},
+		{
+			name:      "invalid pointer query",
+			target:    "/?unit=mm",
+			body:      `{"key":"enter","type":"key"}`,
+			wantField: "Event.Unit",
+			wantRule:  "enum",
+		},
+		{
+			name: "empty",
+			body: ``,
+			want: nil,
+		},
+		{
+			name:     "trailing data",
+			body:     `{"type":"click","x":4} {}`,
+			wantName: "event",
+		},
+		{
+			name:     "missing discriminator",
+			body:     `{"x":4}`,
+			wantName: "type",
+			wantErr:  ErrMissing,
+		},
+		{
+			name:     "unknown type",
+			body:     `{"type":"scroll"}`,
+			wantName: "type",
+		},
+		{
+			name:     "unknown field",
+			body:     `{"type":"click","y":4}`,
+			wantName: "/y",
+			wantErr:  ErrUnknownField,
+		},
+		{
+			name:      "invalid",
+			body:      `{"type":"key","key":""}`,
+			wantField: "Event.Key",
+			wantRule:  "minLen",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == "" {
+				target = "/"
+			}
+			r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("X-Source", "web")
+
+			var req request
+			err := decoder.Decode(r, &req)
+			if tt.wantName == "" && tt.wantRule == "" {
+				if err != nil {
+					t.Fatalf("Decode() unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(req.Event, tt.want) {
+					t.Errorf("Decode() = %#v, want %#v", req.Event, tt.want)
+				}
+				return
+			}
+
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Decode() error = %v, want *FieldError", err)
+			}
+			if tt.wantName != "" && fieldErr.Name != tt.wantName {
+				t.Errorf("Decode() error name = %q, want %q", fieldErr.Name, tt.wantName)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantRule != "" && (fieldErr.Rule != tt.wantRule || fieldErr.Field != tt.wantField) {
+				t.Errorf("Decode() error = %v, want rule %q on %q", err, tt.wantRule, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestDecoder_discriminatorTopLevel(t *testing.T) {
+	decoder := NewDecoder(WithDiscriminator("type", map[string]event{"click": &clickEvent{}}))
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"type":"click","x":-1}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var e event
+	err := decoder.Decode(r, &e)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Rule != "min" {
+		t.Fatalf("Decode() error = %v, want min rule", err)
+	}
+	if _, ok := e.(*clickEvent); !ok {
+		t.Errorf("Decode() = %T, want *clickEvent", e)
+	}
+}
+
+func TestWithDiscriminator_nilMapping(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("WithDiscriminator() did not panic for a nil mapping")
+		}
+	}()
+	NewDecoder(WithDiscriminator("type", map[string]event{"click": nil}))
+}
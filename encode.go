@@ -0,0 +1,521 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encode an HTTP request from the provided value, the inverse of Decode.
+// Path templates in the base URL, i.e. {id}, are filled from path tags, query parameters from query tags,
+// and headers from header tags, skipping zero values other than pointers and wrappers. The body is encoded from the field with a body tag, or otherwise from the
+// JSON encoding of the fields without a tag, leaving out unset wrapper fields. Values other than structs are encoded as a JSON body.
+func Encode(method, baseURL string, v interface{}) (*http.Request, error) {
+	e, err := newEncoder(baseURL, v)
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := pathVariable(e.path); ok {
+		return nil, &FieldError{Source: "path", Name: name, Err: ErrMissing}
+	}
+
+	u, err := url.Parse(e.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.query) > 0 {
+		query := u.Query()
+		for key, values := range e.query {
+			query[key] = append(query[key], values...)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	r, err := http.NewRequest(method, u.String(), e.body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range e.header {
+		r.Header[key] = values
+	}
+	return r, nil
+}
+
+// encoder collects the parts of a request or response encoded from struct fields
+type encoder struct {
+	path    string
+	query   url.Values
+	header  http.Header
+	status  int
+	body    io.Reader
+	hasBody bool
+}
+
+// newEncoder encodes the value into the parts of a request or response, filling the path template
+func newEncoder(path string, v interface{}) (*encoder, error) {
+	e := &encoder{path: path, query: url.Values{}, header: http.Header{}}
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if !val.IsValid() || val.Kind() == reflect.Pointer {
+		return e, nil
+	}
+	if val.Kind() != reflect.Struct {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		e.body = bytes.NewReader(b)
+		e.header.Set("Content-Type", "application/json")
+		return e, nil
+	}
+
+	// copy the struct so wrapper fields are addressable
+	data := reflect.New(val.Type())
+	data.Elem().Set(val)
+	if err := e.encodeStruct(data.Elem(), ""); err != nil {
+		return nil, err
+	}
+	if !e.hasBody {
+		if err := e.encodeJSONFields(data.Elem()); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		typ := t.Field(i)
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		name := fieldPath(path, typ.Name)
+
+		if _, ok := asWrapper(field); !ok && typ.Type.Kind() == reflect.Struct {
+			if err := e.encodeStruct(field, name); err != nil {
+				return err
+			}
+		}
+
+		for _, source := range sourceTags {
+			tag := typ.Tag.Get(source)
+			if tag == "" {
+				continue
+			}
+			key, opts := parseTag(tag)
+
+			var err error
+			switch source {
+			case "query":
+				err = e.encodeQuery(field, key, opts)
+			case "path":
+				err = e.encodePath(field, key)
+			case "header":
+				err = e.encodeHeader(field, key, opts)
+			case "status":
+				err = e.encodeStatus(field)
+			case "body":
+				e.hasBody = true
+				err = e.encodeBody(field, key)
+			}
+			if err != nil {
+				return &FieldError{Field: name, Source: source, Name: key, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func (e *encoder) encodeQuery(field reflect.Value, name string, opts tagOptions) error {
+	if isZero(field) {
+		return nil
+	}
+	values, ok, err := formatValues(field)
+	if err != nil || !ok || (opts.Contains("omitempty") && isEmpty(field)) {
+		return err
+	}
+	if isSlice(field) && !opts.Contains("explode") {
+		values = []string{strings.Join(values, ",")}
+	}
+	for _, value := range values {
+		e.query.Add(name, value)
+	}
+	return nil
+}
+
+func (e *encoder) encodePath(field reflect.Value, name string) error {
+	values, ok, err := formatValues(field)
+	if err != nil || !ok {
+		return err
+	}
+	e.path = fillPath(e.path, name, url.PathEscape(strings.Join(values, ",")))
+	return nil
+}
+
+func (e *encoder) encodeHeader(field reflect.Value, name string, opts tagOptions) error {
+	if isZero(field) {
+		return nil
+	}
+	values, ok, err := formatValues(field)
+	if err != nil || !ok || (opts.Contains("omitempty") && isEmpty(field)) {
+		return err
+	}
+	for _, value := range values {
+		e.header.Add(name, value)
+	}
+	return nil
+}
+
+// isZero reports whether the field holds a zero value that is not encoded, so the decoder treats the value as absent,
+// applying its default and skipping its validation. Pointers and wrapper fields holding zero values are encoded.
+func isZero(field reflect.Value) bool {
+	if _, ok := asWrapper(field); ok {
+		return false
+	}
+	switch field.Kind() {
+	case reflect.Pointer:
+		return false
+	case reflect.Slice:
+		return field.Len() == 0
+	}
+	return field.IsZero()
+}
+
+// encodeStatus encodes the status code of a response
+func (e *encoder) encodeStatus(field reflect.Value) error {
+	values, ok, err := formatValues(field)
+	if err != nil || !ok || len(values) != 1 {
+		return err
+	}
+	status, err := strconv.Atoi(values[0])
+	if err != nil {
+		return err
+	}
+	e.status = status
+	return nil
+}
+
+// encodeBody encodes the body from a body tag field, the first field with a value is the body.
+// Fields with the raw name, or of a string or byte slice type, are the body as it is,
+// with the content type of the other body fields.
+func (e *encoder) encodeBody(field reflect.Value, name string) error {
+	if e.body != nil {
+		if name != "raw" && e.header.Get("Content-Type") == "" {
+			e.header.Set("Content-Type", name)
+		}
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		if field.IsNil() {
+			return nil
+		}
+	}
+	contentType := name
+	if name == "raw" {
+		contentType = ""
+	}
+
+	switch {
+	case field.Type() == readerType || field.Type() == readCloserType:
+		e.body = field.Interface().(io.Reader)
+	case name == "raw" || isRawType(field.Type()):
+		if field.Len() == 0 {
+			return nil
+		}
+		if field.Kind() == reflect.String {
+			e.body = strings.NewReader(field.String())
+		} else {
+			e.body = bytes.NewReader(field.Bytes())
+		}
+	default:
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return fmt.Errorf("unsupported content type: %s", contentType)
+		}
+		b, err := json.Marshal(field.Interface())
+		if err != nil {
+			return err
+		}
+		e.body = bytes.NewReader(b)
+	}
+
+	if contentType != "" {
+		e.header.Set("Content-Type", contentType)
+	}
+	return nil
+}
+
+// encodeJSONFields encodes the JSON body from the struct fields without a source tag, omitting the body if there are none
+func (e *encoder) encodeJSONFields(v reflect.Value) error {
+	b, err := json.Marshal(v.Addr().Interface())
+	if err != nil {
+		return err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(b, &object); err != nil {
+		// types encoding JSON themselves are encoded as they are
+		e.body = bytes.NewReader(b)
+		e.header.Set("Content-Type", "application/json")
+		return nil
+	}
+	if hasStrippedFields(v.Type()) {
+		if err := stripFields(v, object); err != nil {
+			return err
+		}
+		if b, err = json.Marshal(object); err != nil {
+			return err
+		}
+	}
+	if len(object) == 0 {
+		return nil
+	}
+
+	e.body = bytes.NewReader(b)
+	e.header.Set("Content-Type", "application/json")
+	return nil
+}
+
+// stripFields removes the JSON object keys of struct fields with a source tag, of unset wrapper fields,
+// and the nested objects left empty
+func stripFields(v reflect.Value, object map[string]json.RawMessage) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _ := parseTag(tag)
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			if err := stripFields(v.Field(i), object); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		raw, ok := object[name]
+		if !ok {
+			continue
+		}
+
+		if hasSourceTag(f) || isUnset(v.Field(i)) {
+			delete(object, name)
+			continue
+		}
+		if f.Type.Kind() != reflect.Struct || reflect.PointerTo(f.Type).Implements(wrapperType) {
+			continue
+		}
+		var nested map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &nested); err != nil {
+			continue
+		}
+		if err := stripFields(v.Field(i), nested); err != nil {
+			return err
+		}
+		if len(nested) == 0 {
+			delete(object, name)
+			continue
+		}
+		b, err := json.Marshal(nested)
+		if err != nil {
+			return err
+		}
+		object[name] = b
+	}
+	return nil
+}
+
+// isUnset reports whether the field is a wrapper without a value, and not null
+func isUnset(field reflect.Value) bool {
+	w, ok := asWrapper(field)
+	if !ok {
+		return false
+	}
+	if n, ok := w.(interface{ IsNull() bool }); ok && n.IsNull() {
+		return false
+	}
+	_, set := w.wrapped()
+	return !set
+}
+
+// hasStrippedFields reports whether the struct, or a struct nested in it, has fields with a source tag or of a wrapper type
+func hasStrippedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if hasSourceTag(f) || reflect.PointerTo(f.Type).Implements(wrapperType) {
+			return true
+		}
+		if f.Type.Kind() == reflect.Struct && hasStrippedFields(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSourceTag reports whether the struct field is assigned from the request by a source tag
+func hasSourceTag(f reflect.StructField) bool {
+	for _, source := range sourceTags {
+		if _, ok := f.Tag.Lookup(source); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fillPath replaces the path template variable with the value, including variables with a pattern, i.e. {id:[0-9]+}
+func fillPath(path, name, value string) string {
+	for offset := 0; offset < len(path); {
+		i := strings.Index(path[offset:], "{"+name)
+		if i < 0 {
+			return path
+		}
+		start := offset + i
+		end := start + len(name) + 1
+		if end >= len(path) || (path[end] != '}' && path[end] != ':') {
+			offset = end
+			continue
+		}
+		depth := 0
+		for ; end < len(path); end++ {
+			if path[end] == '{' {
+				depth++
+			} else if path[end] == '}' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		if end == len(path) {
+			return path
+		}
+		path = path[:start] + value + path[end+1:]
+		offset = start + len(value)
+	}
+	return path
+}
+
+// pathVariable returns the name of the first path template variable left in the path
+func pathVariable(path string) (string, bool) {
+	start := strings.Index(path, "{")
+	if start < 0 {
+		return "", false
+	}
+	name := path[start+1:]
+	if end := strings.IndexAny(name, ":}"); end >= 0 {
+		name = name[:end]
+	}
+	return name, true
+}
+
+// formatValues formats the field value into strings, reporting whether the field has a value.
+// Slices are formatted into a string for each item, and null Nullable fields are formatted as null.
+func formatValues(field reflect.Value) ([]string, bool, error) {
+	if w, ok := asWrapper(field); ok {
+		if n, ok := w.(interface{ IsNull() bool }); ok && n.IsNull() {
+			return []string{"null"}, true, nil
+		}
+		v, set := w.wrapped()
+		if !set {
+			return nil, false, nil
+		}
+		return formatValues(v)
+	}
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		if field.IsNil() {
+			return nil, false, nil
+		}
+		values := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			value, ok, err := formatValue(field.Index(i))
+			if err != nil {
+				return nil, true, err
+			}
+			if ok {
+				values = append(values, value)
+			}
+		}
+		return values, true, nil
+	}
+	value, ok, err := formatValue(field)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	return []string{value}, true, nil
+}
+
+// formatValue formats the field value into a string, reporting whether the field has a value
+func formatValue(field reflect.Value) (string, bool, error) {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return "", false, nil
+		}
+		field = field.Elem()
+	}
+	value, err := format(field.Interface())
+	return value, true, err
+}
+
+// format the value into the string it is resolved from
+func format(t interface{}) (string, error) {
+	switch v := t.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return v.String(), nil
+	case netip.Addr:
+		return v.String(), nil
+	case netip.AddrPort:
+		return v.String(), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case complex128:
+		return strconv.FormatComplex(v, 'g', -1, 128), nil
+	case complex64:
+		return strconv.FormatComplex(complex128(v), 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported type: %v", reflect.TypeOf(t))
+	}
+}
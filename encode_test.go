@@ -0,0 +1,281 @@
+package request
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type encodeFilter struct {
+	Name string `query:"name,omitempty"`
+}
+
+type encodeRequest struct {
+	ID      int              `path:"id"`
+	Slug    string           `path:"slug"`
+	Tags    []string         `query:"tags"`
+	States  []string         `query:"state,explode"`
+	Limit   *int             `query:"limit"`
+	Since   time.Time        `query:"since"`
+	Page    Optional[int]    `query:"page"`
+	Parent  Nullable[string] `query:"parent"`
+	Trace   []string         `header:"X-Trace"`
+	Timeout time.Duration    `header:"X-Timeout"`
+	Filter  encodeFilter
+	Title   string `json:"title"`
+	Count   int    `json:"count,omitempty"`
+}
+
+func TestEncode(t *testing.T) {
+	limit := 10
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	in := encodeRequest{
+		ID:      7,
+		Slug:    "a b/c",
+		Tags:    []string{"x", "y"},
+		States:  []string{"idle", "active"},
+		Limit:   &limit,
+		Since:   since,
+		Trace:   []string{"1", "2"},
+		Timeout: 5 * time.Second,
+		Filter:  encodeFilter{Name: "adam"},
+		Title:   "hello",
+	}
+	in.Parent.SetNull()
+
+	r, err := Encode(http.MethodPost, "http://www.example.com/users/{id:[0-9]+}/{slug}?v=1", &in)
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	if got, want := r.URL.EscapedPath(), "/users/7/a%20b%2Fc"; got != want {
+		t.Errorf("Encode() path = %q, want %q", got, want)
+	}
+	wantQuery := map[string][]string{
+		"v":      {"1"},
+		"tags":   {"x,y"},
+		"state":  {"idle", "active"},
+		"limit":  {"10"},
+		"since":  {"2024-01-02T03:04:05Z"},
+		"parent": {"null"},
+		"name":   {"adam"},
+	}
+	if got := map[string][]string(r.URL.Query()); !reflect.DeepEqual(got, wantQuery) {
+		t.Errorf("Encode() query = %v, want %v", got, wantQuery)
+	}
+	if got := r.Header.Values("X-Trace"); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("Encode() header = %v", got)
+	}
+	if got := r.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Encode() content type = %q", got)
+	}
+	body, _ := io.ReadAll(r.Body)
+	if got, want := string(body), `{"title":"hello"}`; got != want {
+		t.Errorf("Encode() body = %s, want %s", got, want)
+	}
+
+	// decoding the encoded request returns the same value
+	in.Slug = "a b"
+	r, _ = Encode(http.MethodPost, "http://www.example.com/users/{id}/{slug}", &in)
+	var out encodeRequest
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		if err := Decode(r, &out); err != nil {
+			t.Errorf("Decode() unexpected error: %v", err)
+		}
+	})
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Decode(Encode()) = %+v, want %+v", out, in)
+	}
+}
+
+func TestEncode_body(t *testing.T) {
+	tests := []struct {
+		name            string
+		v               interface{}
+		wantBody        string
+		wantContentType string
+		wantErr         bool
+	}{
+		{
+			name: "no body",
+			v: struct {
+				ID string `query:"id"`
+			}{ID: "1"},
+		},
+		{
+			name: "json body",
+			v: struct {
+				ID    string `query:"id"`
+				Items []int  `body:"application/json"`
+			}{ID: "1", Items: []int{1, 2}},
+			wantBody:        `[1,2]`,
+			wantContentType: "application/json",
+		},
+		{
+			name: "nil body",
+			v: struct {
+				Items []int `body:"application/json"`
+			}{},
+		},
+		{
+			name: "raw body",
+			v: struct {
+				Raw  []byte         `body:"raw"`
+				Data map[string]int `body:"application/json"`
+			}{Raw: []byte(`{"a":1}`), Data: map[string]int{"a": 2}},
+			wantBody:        `{"a":1}`,
+			wantContentType: "application/json",
+		},
+		{
+			name: "text body",
+			v: struct {
+				Text string `body:"text/plain"`
+			}{Text: "hello"},
+			wantBody:        "hello",
+			wantContentType: "text/plain",
+		},
+		{
+			name: "reader body",
+			v: struct {
+				File io.Reader `body:"application/octet-stream"`
+			}{File: strings.NewReader("data")},
+			wantBody:        "data",
+			wantContentType: "application/octet-stream",
+		},
+		{
+			name: "unset wrapper fields",
+			v: struct {
+				Age   Optional[int]    `json:"age"`
+				Name  Nullable[string] `json:"name"`
+				Email Optional[string] `json:"email"`
+			}{Email: Optional[string]{value: "a@b.c", set: true}},
+			wantBody:        `{"email":"a@b.c"}`,
+			wantContentType: "application/json",
+		},
+		{
+			name: "null wrapper field",
+			v: struct {
+				Age  Optional[int]    `json:"age"`
+				Name Nullable[string] `json:"name"`
+			}{Name: Nullable[string]{set: true, null: true}},
+			wantBody:        `{"name":null}`,
+			wantContentType: "application/json",
+		},
+		{
+			name: "unset patch",
+			v: struct {
+				Age Optional[int] `json:"age"`
+			}{},
+		},
+		{
+			name:            "non struct",
+			v:               []string{"a"},
+			wantBody:        `["a"]`,
+			wantContentType: "application/json",
+		},
+		{
+			name: "unsupported content type",
+			v: struct {
+				Items []int `body:"text/csv"`
+			}{Items: []int{1}},
+			wantErr: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Encode(http.MethodPost, "http://www.example.com/", tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var body []byte
+			if r.Body != nil {
+				body, _ = io.ReadAll(r.Body)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("Encode() body = %q, want %q", body, tt.wantBody)
+			}
+			if got := r.Header.Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Encode() content type = %q, want %q", got, tt.wantContentType)
+			}
+		})
+	}
+}
+
+func TestEncode_errors(t *testing.T) {
+	_, err := Encode(http.MethodGet, "http://www.example.com/users/{id}", struct {
+		ID *int `path:"id"`
+	}{})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || !errors.Is(err, ErrMissing) || fieldErr.Name != "id" {
+		t.Errorf("Encode() error = %v, want missing path variable", err)
+	}
+
+	_, err = Encode(http.MethodGet, "http://www.example.com/", struct {
+		Value struct{ A int } `query:"value"`
+	}{Value: struct{ A int }{A: 1}})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Value" || fieldErr.Source != "query" {
+		t.Errorf("Encode() error = %v, want unsupported type", err)
+	}
+}
+
+func TestEncode_zeroValues(t *testing.T) {
+	type request struct {
+		Sort  string        `query:"sort" enum:"asc,desc"`
+		Limit int           `query:"limit" default:"20"`
+		Page  *int          `query:"page"`
+		Size  Optional[int] `query:"size"`
+		Token string        `header:"Authorization"`
+	}
+	in := request{Page: new(int)}
+	in.Size.Set(0)
+	r, err := Encode(http.MethodGet, "http://www.example.com/users", in)
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	if got, want := r.URL.RawQuery, "page=0&size=0"; got != want {
+		t.Errorf("Encode() query = %q, want %q", got, want)
+	}
+	if _, ok := r.Header["Authorization"]; ok {
+		t.Errorf("Encode() header = %v, want no Authorization header", r.Header)
+	}
+
+	var out request
+	if err := Decode(r, &out); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	if out.Limit != 20 || out.Page == nil || *out.Page != 0 || !out.Size.IsSet() {
+		t.Errorf("Decode(Encode()) = %+v, want defaults and zero pointer and optional values", out)
+	}
+}
+
+func Test_fillPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		name  string
+		value string
+		want  string
+	}{
+		{path: "/users/{id}", name: "id", value: "1", want: "/users/1"},
+		{path: "/users/{id:[0-9]{1,3}}/posts", name: "id", value: "1", want: "/users/1/posts"},
+		{path: "/users/{identity}/{id}", name: "id", value: "1", want: "/users/{identity}/1"},
+		{path: "/users/{id}/{id}", name: "id", value: "1", want: "/users/1/1"},
+		{path: "/users/{name}", name: "id", value: "1", want: "/users/{name}"},
+	}
+	for _, tt := range tests {
+		if got := fillPath(tt.path, tt.name, tt.value); got != tt.want {
+			t.Errorf("fillPath(%q, %q) = %q, want %q", tt.path, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrMissing is reported when a required field is not supplied by the request
+var ErrMissing = errors.New("missing required value")
+
+// ErrBodyTooLarge is reported when the request body exceeds the decoder's maximum size, answered with a 413 Request Entity Too Large
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ErrUnsupportedEncoding is reported when the request body has a Content-Encoding the decoder cannot decompress, answered with a 415 Unsupported Media Type
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// ErrUnknownField is reported by strict decoding when the request body has a field the struct does not
+var ErrUnknownField = errors.New("unknown field")
+
+// ErrInvalidPatch is reported when a JSON Patch or JSON Merge Patch document cannot be validated or applied
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// ErrNotAcceptable is reported by a Handler when the response cannot be written in a format the request accepts, answered with a 406 Not Acceptable
+var ErrNotAcceptable = errors.New("not acceptable")
+
+// FieldError describes a failure to decode a single field from the request
+type FieldError struct {
+	// Field is the path to the struct field, i.e. Request.State
+	Field string
+	// Source is the struct tag the value is pulled from, i.e. query
+	Source string
+	// Name is the name the value is looked up by in the source
+	Name string
+	// Rule is the validation tag the value failed, i.e. max
+	Rule string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	msg := "invalid field"
+	if e.Field != "" {
+		msg += " " + e.Field
+	}
+	if e.Source != "" {
+		msg += fmt.Sprintf(" (%s %q)", e.Source, e.Name)
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// LineError describes a failure to decode a line of the request body
+type LineError struct {
+	// Line is the line number in the request body, starting at 1
+	Line int
+	// Column is the name of the column in the line, for bodies with columns such as CSV
+	Column string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *LineError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("invalid line %d column %q: %v", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("invalid line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseError is returned by Do when the response has a status code other than 2xx
+type ResponseError struct {
+	// StatusCode is the status code of the response, i.e. 404
+	StatusCode int
+	// Header is the header of the response
+	Header http.Header
+	// Body is the start of the response body, limited to 64KB
+	Body []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("unexpected response status: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
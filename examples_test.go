@@ -1,10 +1,15 @@
 package request
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -288,3 +293,680 @@ func ExampleDecode_pointers() {
 	// Output:
 	// {Request:{Active:true NilActive:nil State:idle NilState:nil Delay:60 NilDelay:nil}}
 }
+
+func ExampleDecode_required() {
+	r := mux.NewRouter()
+	r.Handle("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     string `query:"id,required"`
+			Tenant string `header:"X-Tenant,required"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("%+v\n", req)
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://www.example.com/users?id=adam", nil)
+	req.Header.Set("X-Tenant", "acme")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	req, _ = http.NewRequest(http.MethodGet, "http://www.example.com/users?id=", nil)
+	req.Header.Set("X-Tenant", "acme")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Output:
+	// {ID:adam Tenant:acme}
+	// invalid field ID (query "id"): missing required value
+}
+
+func ExampleDecode_default() {
+	r := mux.NewRouter()
+	r.Handle("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Limit   int           `query:"limit" default:"20"`
+			Sort    []string      `query:"sort" default:"name,age"`
+			Timeout time.Duration `header:"X-Timeout" default:"5s"`
+			State   string        `json:"state" default:"idle"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+		}
+
+		fmt.Printf("%+v\n", req)
+	}))
+
+	body := `{"state":"active"}`
+	req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/users?limit=5", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code == http.StatusBadRequest {
+		fmt.Println("decode failed")
+	}
+	// Output:
+	// {Limit:5 Sort:[name age] Timeout:5s State:active}
+}
+
+func ExampleDecode_validation() {
+	r := mux.NewRouter()
+	r.Handle("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Limit int    `query:"limit" min:"1" max:"100"`
+			Sort  string `query:"sort" enum:"asc,desc"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("%+v\n", req)
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://www.example.com/users?limit=20&sort=asc", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	req, _ = http.NewRequest(http.MethodGet, "http://www.example.com/users?limit=500&sort=asc", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Output:
+	// {Limit:20 Sort:asc}
+	// invalid field Limit (query "limit"): must be at most 100
+}
+
+type searchRequest struct {
+	Query string    `query:"q"`
+	Start time.Time `query:"start"`
+	End   time.Time `query:"end"`
+}
+
+func (s *searchRequest) Normalize() {
+	s.Query = strings.TrimSpace(s.Query)
+}
+
+func (s *searchRequest) Validate() error {
+	if s.End.Before(s.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	return nil
+}
+
+func ExampleDecode_hooks() {
+	r := mux.NewRouter()
+	r.Handle("/search", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req searchRequest
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("%q\n", req.Query)
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://www.example.com/search?q=+adam+&start=2021-10-22T11:01:00Z&end=2021-10-23T11:01:00Z", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	req, _ = http.NewRequest(http.MethodGet, "http://www.example.com/search?q=adam&start=2021-10-22T11:01:00Z&end=2021-10-21T11:01:00Z", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Output:
+	// "adam"
+	// end must be after start
+}
+
+func ExampleDecode_optional() {
+	r := mux.NewRouter()
+	r.Handle("/users/{user}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			User   string           `path:"user"`
+			Active Optional[bool]   `query:"active"`
+			State  Optional[string] `json:"state"`
+			Delay  Optional[int]    `header:"X-DELAY"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+		}
+
+		fmt.Printf("active set:%t state set:%t delay set:%t\n", req.Active.IsSet(), req.State.IsSet(), req.Delay.IsSet())
+	}))
+
+	body := `{"state":""}`
+	req, _ := http.NewRequest(http.MethodPatch, "http://www.example.com/users/adam?active=false", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code == http.StatusBadRequest {
+		fmt.Println("decode failed")
+	}
+	// Output:
+	// active set:true state set:true delay set:false
+}
+
+func ExampleDecode_nullable() {
+	r := mux.NewRouter()
+	r.Handle("/users/{user}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name  Nullable[string] `json:"name"`
+			State Nullable[string] `json:"state"`
+			Email Nullable[string] `json:"email"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+		}
+
+		describe := func(n Nullable[string]) string {
+			switch {
+			case !n.IsSet():
+				return "absent"
+			case n.IsNull():
+				return "null"
+			}
+			return n.Value()
+		}
+		fmt.Printf("{Name:%s State:%s Email:%s}\n", describe(req.Name), describe(req.State), describe(req.Email))
+	}))
+
+	body := `{"name":null,"state":"idle"}`
+	req, _ := http.NewRequest(http.MethodPatch, "http://www.example.com/users/adam", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code == http.StatusBadRequest {
+		fmt.Println("decode failed")
+	}
+	// Output:
+	// {Name:null State:idle Email:absent}
+}
+
+func ExampleDecode_jsonPatch() {
+	type User struct {
+		Name  string   `json:"name"`
+		Roles []string `json:"roles"`
+	}
+	user := User{Name: "adam", Roles: []string{"reader"}}
+
+	r := mux.NewRouter()
+	r.Handle("/users/{user}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			User  string    `path:"user"`
+			Patch JSONPatch `body:"application/json-patch+json"`
+		}
+		err := Decode(r, &req)
+		if err == nil {
+			err = req.Patch.Validate(&user)
+		}
+		if err == nil {
+			err = req.Patch.Apply(&user)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+		}
+
+		fmt.Printf("%+v\n", user)
+	}))
+
+	body := `[{"op":"replace","path":"/name","value":"eve"},{"op":"add","path":"/roles/-","value":"writer"}]`
+	req, _ := http.NewRequest(http.MethodPatch, "http://www.example.com/users/adam", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code == http.StatusBadRequest {
+		fmt.Println("decode failed")
+	}
+	// Output:
+	// {Name:eve Roles:[reader writer]}
+}
+
+func ExampleDecode_mergePatch() {
+	type User struct {
+		Name  string `json:"name"`
+		Email string `json:"email,omitempty"`
+	}
+	user := User{Name: "adam", Email: "adam@example.com"}
+
+	r := mux.NewRouter()
+	r.Handle("/users/{user}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Patch MergePatch `body:"application/merge-patch+json"`
+		}
+		err := Decode(r, &req)
+		if err == nil {
+			err = req.Patch.Apply(&user)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+		}
+
+		fmt.Printf("%+v\n", user)
+	}))
+
+	body := `{"name":"eve","email":null}`
+	req, _ := http.NewRequest(http.MethodPatch, "http://www.example.com/users/adam", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code == http.StatusBadRequest {
+		fmt.Println("decode failed")
+	}
+	// Output:
+	// {Name:eve Email:}
+}
+
+func ExampleDecoder() {
+	decoder := NewDecoder(WithMaxBodyBytes(16))
+
+	r := mux.NewRouter()
+	r.Handle("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			State string `json:"state"`
+		}
+		err := decoder.Decode(r, &req)
+		if errors.Is(err, ErrBodyTooLarge) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("%+v\n", req)
+	}))
+
+	req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/users", strings.NewReader(`{"state":"idle"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	req, _ = http.NewRequest(http.MethodPost, "http://www.example.com/users", strings.NewReader(`{"state":"active"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Output:
+	// {State:idle}
+	// request body too large
+}
+
+func ExampleDecode_array() {
+	r := mux.NewRouter()
+	r.Handle("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req []struct {
+			State string `json:"state"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+		}
+
+		fmt.Printf("%+v\n", req)
+	}))
+
+	body := `[{"state":"idle"},{"state":"active"}]`
+	req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code == http.StatusBadRequest {
+		fmt.Println("decode failed")
+	}
+	// Output:
+	// [{State:idle} {State:active}]
+}
+
+func ExampleDecode_stream() {
+	r := mux.NewRouter()
+	r.Handle("/events", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Source string `query:"source"`
+			Events Stream[struct {
+				State string `json:"state"`
+			}] `body:"application/x-ndjson"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+		}
+		defer req.Events.Close()
+
+		for req.Events.Next() {
+			fmt.Printf("%s %+v\n", req.Source, req.Events.Value())
+		}
+		if err := req.Events.Err(); err != nil {
+			fmt.Println(err.Error())
+		}
+	}))
+
+	body := "{\"state\":\"idle\"}\n{\"state\":\"active\"}\n{\"state\":}\n"
+	req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/events?source=mobile", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code == http.StatusBadRequest {
+		fmt.Println("decode failed")
+	}
+	// Output:
+	// mobile {State:idle}
+	// mobile {State:active}
+	// invalid line 3: invalid character '}' looking for beginning of value
+}
+
+func ExampleDecode_csv() {
+	r := mux.NewRouter()
+	r.Handle("/users/import", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			DryRun bool `query:"dryRun"`
+			Users  []struct {
+				Name   string `csv:"name,required"`
+				Active bool   `csv:"active"`
+				Age    int    `csv:"age"`
+			} `body:"text/csv"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("%+v\n", req)
+	}))
+
+	body := "name,active,age\nadam,true,30\neve,false,28\n"
+	req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/users/import?dryRun=true", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	body = "name,active,age\nadam,true,30\neve,maybe,28\n"
+	req, _ = http.NewRequest(http.MethodPost, "http://www.example.com/users/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Output:
+	// {DryRun:true Users:[{Name:adam Active:true Age:30} {Name:eve Active:false Age:28}]}
+	// invalid field Users (body "text/csv"): invalid line 3 column "active": strconv.ParseBool: parsing "maybe": invalid syntax
+}
+
+type shape interface{}
+
+type circle struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius" min:"0"`
+}
+
+type rectangle struct {
+	Kind   string  `json:"kind"`
+	Width  float64 `json:"width" min:"0"`
+	Height float64 `json:"height" min:"0"`
+}
+
+func ExampleWithDiscriminator() {
+	decoder := NewDecoder(WithDiscriminator("kind", map[string]shape{
+		"circle":    circle{},
+		"rectangle": rectangle{},
+	}))
+
+	r := mux.NewRouter()
+	r.Handle("/shapes", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Shape shape `body:"application/json"`
+		}
+		err := decoder.Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("%T %+v\n", req.Shape, req.Shape)
+	}))
+
+	for _, body := range []string{
+		`{"kind":"circle","radius":2}`,
+		`{"kind":"rectangle","width":3,"height":-1}`,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/shapes", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+	}
+	// Output:
+	// request.circle {Kind:circle Radius:2}
+	// invalid field Shape.Height: must be at least 0
+}
+
+func ExampleEncode() {
+	type getUsers struct {
+		OrgID  string   `path:"orgID"`
+		States []string `query:"state"`
+		Limit  *int     `query:"limit"`
+		Token  string   `header:"Authorization"`
+	}
+
+	limit := 10
+	r, err := Encode(http.MethodGet, "http://www.example.com/orgs/{orgID}/users", getUsers{
+		OrgID:  "acme",
+		States: []string{"idle", "active"},
+		Limit:  &limit,
+		Token:  "Bearer token",
+	})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Println(r.Method, r.URL.String())
+	fmt.Println(r.Header.Get("Authorization"))
+	// Output:
+	// GET http://www.example.com/orgs/acme/users?limit=10&state=idle%2Cactive
+	// Bearer token
+}
+
+func ExampleDo() {
+	type getUser struct {
+		ID string `path:"id"`
+	}
+	type user struct {
+		RequestID string `header:"X-Request-ID"`
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req getUser
+		_ = Decode(r, &req)
+		if req.ID != "1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-ID", "abc")
+		_, _ = w.Write([]byte(`{"id":"1","name":"adam"}`))
+	}))
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL}
+	resp, err := Do[getUser, user](context.Background(), client, http.MethodGet, "/users/{id}", getUser{ID: "1"})
+	fmt.Printf("%+v %v\n", resp, err)
+
+	_, err = Do[getUser, user](context.Background(), client, http.MethodGet, "/users/{id}", getUser{ID: "2"})
+	fmt.Println(err)
+	// Output:
+	// {RequestID:abc ID:1 Name:adam} <nil>
+	// unexpected response status: 404 Not Found
+}
+
+func ExampleDecodeResponse() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v2"`)
+		w.Header().Set("X-RateLimit-Remaining", "99")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"name":"adam"}`))
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var user struct {
+		Status    int    `status:"code"`
+		ETag      string `header:"ETag"`
+		Remaining int    `header:"X-RateLimit-Remaining"`
+		ID        string `json:"id"`
+	}
+	if err := DecodeResponse(resp, &user); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fmt.Printf("%+v\n", user)
+	// Output:
+	// {Status:201 ETag:"v2" Remaining:99 ID:1}
+}
+
+func ExampleHandler() {
+	type createUser struct {
+		OrgID string `path:"orgID"`
+		Name  string `json:"name" minLen:"1"`
+	}
+	type user struct {
+		Status   int    `status:"code"`
+		Location string `header:"Location"`
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/orgs/{orgID}/users", Handler(func(ctx context.Context, req createUser) (user, error) {
+		return user{Status: http.StatusCreated, Location: "/orgs/" + req.OrgID + "/users/1", ID: "1", Name: req.Name}, nil
+	}))
+
+	for _, body := range []string{`{"name":"adam"}`, `{"name":""}`} {
+		req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/orgs/acme/users", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		fmt.Println(rec.Code, rec.Header().Get("Location"), strings.TrimSpace(rec.Body.String()))
+	}
+	// Output:
+	// 201 /orgs/acme/users/1 {"id":"1","name":"adam"}
+	// 400  {"error":"invalid field Name: must be at least 1 characters"}
+}
+
+func ExampleMiddleware() {
+	type page struct {
+		TenantID string `header:"X-Tenant-ID,required"`
+		Limit    int    `query:"limit" default:"20"`
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/users", Middleware[page](http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := FromContext[page](r.Context())
+		fmt.Printf("%+v\n", p)
+	})))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://www.example.com/users", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	req, _ = http.NewRequest(http.MethodGet, "http://www.example.com/users?limit=5", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	fmt.Println(rec.Code, strings.TrimSpace(rec.Body.String()))
+	// Output:
+	// {TenantID:acme Limit:20}
+	// 400 {"error":"invalid field TenantID (header \"X-Tenant-ID\"): missing required value"}
+}
+
+type userKey struct{}
+
+func ExampleWithContextKey() {
+	decoder := NewDecoder(WithContextKey("user", userKey{}))
+
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), userKey{}, r.Header.Get("X-User"))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+
+	r := mux.NewRouter()
+	r.Use(auth)
+	r.Handle("/notes", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			User string `ctx:"user,required"`
+			Text string `json:"text"`
+		}
+		err := decoder.Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("%+v\n", req)
+	}))
+
+	req, _ := http.NewRequest(http.MethodPost, "http://www.example.com/notes", strings.NewReader(`{"text":"hello","User":"eve"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", "adam")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Output:
+	// {User:adam Text:hello}
+}
+
+func ExampleDecode_request() {
+	r := mux.NewRouter()
+	r.Handle("/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method   string         `request:"method"`
+			Scheme   string         `request:"scheme"`
+			Host     string         `request:"host"`
+			ClientIP netip.Addr     `request:"remoteAddr"`
+			Client   netip.AddrPort `request:"remoteAddr"`
+			URL      *url.URL       `request:"url"`
+		}
+		err := Decode(r, &req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Println(req.Method, req.Scheme, req.Host, req.ClientIP, req.Client, req.URL)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://www.example.com/users?limit=5", nil)
+	req.RemoteAddr = "203.0.113.7:52100"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Output:
+	// GET http www.example.com 203.0.113.7 203.0.113.7:52100 http://www.example.com/users?limit=5
+}
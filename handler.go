@@ -0,0 +1,156 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrorHandler writes the response for an error decoding a request, or returned by a handler
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// Handler adapts a func to an http.Handler, decoding the request into Req, calling the func, and writing the response from Resp.
+// Requests that fail to decode are answered with the status code of the error, i.e. 400 Bad Request.
+// The response is written from the status, header and body tags of Resp, the inverse of DecodeResponse,
+// and responses that cannot be written in a format the request accepts are answered with a 406 Not Acceptable.
+// Errors are written by the error handler of the options, WriteError by default.
+func Handler[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error), opts ...Option) http.Handler {
+	d := NewDecoder(opts...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if err := d.Decode(r, &req); err != nil {
+			d.errorHandler(w, r, &statusError{code: statusCode(err, http.StatusBadRequest), err: err})
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			d.errorHandler(w, r, err)
+			return
+		}
+		if err := writeResponse(w, r, resp); err != nil {
+			d.errorHandler(w, r, err)
+		}
+	})
+}
+
+// writeResponse writes the response encoded from the value, if the request accepts the format of the body
+func writeResponse(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	e, err := newEncoder("", v)
+	if err != nil {
+		return err
+	}
+	if e.body != nil && !accepts(strings.Join(r.Header.Values("Accept"), ","), e.header.Get("Content-Type")) {
+		return ErrNotAcceptable
+	}
+
+	for key, values := range e.header {
+		w.Header()[key] = values
+	}
+	status := e.status
+	if status == 0 {
+		status = http.StatusOK
+		if e.body == nil {
+			status = http.StatusNoContent
+		}
+	}
+	w.WriteHeader(status)
+	if e.body != nil {
+		_, err = io.Copy(w, e.body)
+	}
+	if closer, ok := e.body.(io.Closer); ok {
+		closer.Close()
+	}
+	return err
+}
+
+// accepts reports whether the Accept header accepts the content type, every content type is accepted without an Accept header
+func accepts(accept, contentType string) bool {
+	if accept == "" || contentType == "" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	typ, _, _ := strings.Cut(mediaType, "/")
+	for _, part := range strings.Split(accept, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		if accepted == "*/*" || accepted == typ+"/*" || accepted == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status code to answer an error with.
+// Errors with a StatusCode method use that status code, and otherwise
+// ErrBodyTooLarge is a 413 Request Entity Too Large, ErrUnsupportedEncoding is a 415 Unsupported Media Type,
+// ErrNotAcceptable is a 406 Not Acceptable, field and line errors are a 400 Bad Request,
+// and other errors are a 500 Internal Server Error.
+func StatusCode(err error) int {
+	return statusCode(err, http.StatusInternalServerError)
+}
+
+// statusCode returns the HTTP status code to answer an error with, or the fallback status code for unknown errors
+func statusCode(err error, fallback int) int {
+	var coder interface{ StatusCode() int }
+	var fieldErr *FieldError
+	var lineErr *LineError
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.As(err, &coder):
+		return coder.StatusCode()
+	case errors.Is(err, ErrBodyTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedEncoding):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.As(err, &fieldErr), errors.As(err, &lineErr), errors.Is(err, ErrMissing), errors.Is(err, ErrInvalidPatch):
+		return http.StatusBadRequest
+	default:
+		return fallback
+	}
+}
+
+// statusError is an error answered with a status code, such as a request that failed to decode
+type statusError struct {
+	code int
+	err  error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+func (e *statusError) StatusCode() int {
+	return e.code
+}
+
+// WriteError writes the error as a JSON object with the status code of the error, i.e. {"error":"invalid field ..."}.
+// The messages of server errors are replaced with the status text, so internal details are not exposed.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	code := StatusCode(err)
+	msg := err.Error()
+	if code >= http.StatusInternalServerError {
+		msg = http.StatusText(code)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,248 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type handlerRequest struct {
+	ID    int    `path:"id"`
+	Name  string `json:"name" minLen:"1"`
+	Fail  string `query:"fail"`
+	Empty bool   `query:"empty"`
+}
+
+type handlerResponse struct {
+	Status   int    `status:"code"`
+	Location string `header:"Location"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+}
+
+type conflictError struct{}
+
+func (conflictError) Error() string   { return "user already exists" }
+func (conflictError) StatusCode() int { return http.StatusConflict }
+
+func handleUser(ctx context.Context, req handlerRequest) (*handlerResponse, error) {
+	switch req.Fail {
+	case "conflict":
+		return nil, conflictError{}
+	case "internal":
+		return nil, errors.New("database password is wrong")
+	}
+	if req.Empty {
+		return nil, nil
+	}
+	return &handlerResponse{
+		Status:   http.StatusCreated,
+		Location: fmt.Sprintf("/users/%d", req.ID),
+		ID:       req.ID,
+		Name:     req.Name,
+	}, nil
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		target     string
+		body       string
+		accept     string
+		wantStatus int
+		wantHeader string
+		wantBody   string
+	}{
+		{
+			name:       "success",
+			target:     "/users/1",
+			body:       `{"name":"adam"}`,
+			wantStatus: http.StatusCreated,
+			wantHeader: "/users/1",
+			wantBody:   `{"id":1,"name":"adam"}`,
+		},
+		{
+			name:       "no content",
+			target:     "/users/1?empty=true",
+			body:       `{"name":"adam"}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "accepted",
+			target:     "/users/1",
+			body:       `{"name":"adam"}`,
+			accept:     "text/html, application/*;q=0.5",
+			wantStatus: http.StatusCreated,
+			wantHeader: "/users/1",
+			wantBody:   `{"id":1,"name":"adam"}`,
+		},
+		{
+			name:       "not acceptable",
+			target:     "/users/1",
+			body:       `{"name":"adam"}`,
+			accept:     "text/html",
+			wantStatus: http.StatusNotAcceptable,
+			wantBody:   `{"error":"not acceptable"}` + "\n",
+		},
+		{
+			name:       "invalid field",
+			target:     "/users/1",
+			body:       `{"name":""}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid field Name: must be at least 1 characters"}` + "\n",
+		},
+		{
+			name:       "invalid body",
+			target:     "/users/1",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"unexpected EOF"}` + "\n",
+		},
+		{
+			name:       "body too large",
+			opts:       []Option{WithMaxBodyBytes(4)},
+			target:     "/users/1",
+			body:       `{"name":"adam"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"error":"request body too large"}` + "\n",
+		},
+		{
+			name:       "error with status code",
+			target:     "/users/1?fail=conflict",
+			body:       `{"name":"adam"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":"user already exists"}` + "\n",
+		},
+		{
+			name:       "internal error",
+			target:     "/users/1?fail=internal",
+			body:       `{"name":"adam"}`,
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Internal Server Error"}` + "\n",
+		},
+		{
+			name: "error handler",
+			opts: []Option{WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+				http.Error(w, err.Error(), StatusCode(err))
+			})},
+			target:     "/users/1?fail=conflict",
+			body:       `{"name":"adam"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   "user already exists\n",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			router.Handle("/users/{id}", Handler(handleUser, tt.opts...))
+
+			r := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Handler() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantHeader {
+				t.Errorf("Handler() header = %q, want %q", got, tt.wantHeader)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("Handler() body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_pointerRequest(t *testing.T) {
+	type request struct {
+		ID string `query:"id,required"`
+	}
+	handler := Handler(func(ctx context.Context, req *request) (*handlerResponse, error) {
+		return &handlerResponse{Name: req.ID}, nil
+	})
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "decoded",
+			target:     "/?id=7",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":0,"name":"7"}`,
+		},
+		{
+			name:       "required",
+			target:     "/",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid field ID (query \"id\"): missing required value"}` + "\n",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Handler() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("Handler() body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: nil, want: http.StatusOK},
+		{err: &FieldError{Field: "ID", Err: ErrMissing}, want: http.StatusBadRequest},
+		{err: &LineError{Line: 1, Err: errors.New("invalid")}, want: http.StatusBadRequest},
+		{err: &FieldError{Field: "Body", Err: ErrBodyTooLarge}, want: http.StatusRequestEntityTooLarge},
+		{err: fmt.Errorf("%w: br", ErrUnsupportedEncoding), want: http.StatusUnsupportedMediaType},
+		{err: ErrNotAcceptable, want: http.StatusNotAcceptable},
+		{err: fmt.Errorf("create: %w", conflictError{}), want: http.StatusConflict},
+		{err: errors.New("unknown"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := StatusCode(tt.err); got != tt.want {
+			t.Errorf("StatusCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func Test_accepts(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		want        bool
+	}{
+		{accept: "", contentType: "application/json", want: true},
+		{accept: "*/*", contentType: "application/json", want: true},
+		{accept: "application/json", contentType: "application/json; charset=utf-8", want: true},
+		{accept: "text/*", contentType: "text/plain", want: true},
+		{accept: "text/html, application/json;q=0", contentType: "application/json", want: false},
+		{accept: "text/html", contentType: "application/json", want: false},
+	}
+	for _, tt := range tests {
+		if got := accepts(tt.accept, tt.contentType); got != tt.want {
+			t.Errorf("accepts(%q, %q) = %v, want %v", tt.accept, tt.contentType, got, tt.want)
+		}
+	}
+}
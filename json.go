@@ -0,0 +1,208 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	wrapperType     = reflect.TypeOf((*wrapper)(nil)).Elem()
+)
+
+// jsonValueType dereferences pointers and wrapper types to the type a JSON value is decoded into
+func jsonValueType(t reflect.Type) reflect.Type {
+	for {
+		switch {
+		case t.Kind() == reflect.Pointer:
+			t = t.Elem()
+		case reflect.PointerTo(t).Implements(wrapperType):
+			v, _ := reflect.New(t).Interface().(wrapper).wrapped()
+			t = v.Type()
+		default:
+			return t
+		}
+	}
+}
+
+// jsonOpaque reports whether the type decodes JSON itself, so its expected shape is unknown
+func jsonOpaque(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface || t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+// jsonFieldType looks up the type of the struct field a JSON object key is decoded into.
+// Keys are matched to field names the same way as encoding/json, preferring an exact match.
+func jsonFieldType(t reflect.Type, key string) (reflect.Type, bool) {
+	var fold reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _ := parseTag(tag)
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if typ, ok := jsonFieldType(embedded, key); ok {
+					return typ, true
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return f.Type, true
+		}
+		if fold == nil && strings.EqualFold(name, key) {
+			fold = f.Type
+		}
+	}
+	return fold, fold != nil
+}
+
+// resetJSONFields sets the struct fields represented in JSON to their zero values
+func resetJSONFields(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		field := v.Field(i)
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			resetJSONFields(field)
+			continue
+		}
+		if field.CanSet() {
+			field.Set(reflect.Zero(f.Type))
+		}
+	}
+}
+
+// decodeJSON streams a JSON value into data, reporting whether the body had a value.
+// Data after the value is rejected the same as json.Unmarshal, and strict decoding buffers the value to reject unknown fields.
+func (d *Decoder) decodeJSON(body io.Reader, data interface{}, opts tagOptions) (bool, error) {
+	useNumber := d.useNumber || opts.Contains("useNumber")
+	dec := json.NewDecoder(body)
+	if useNumber {
+		dec.UseNumber()
+	}
+	if !d.strict && !opts.Contains("strict") {
+		if err := dec.Decode(data); err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			return true, err
+		}
+		return true, trailingData(dec, body)
+	}
+
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return true, err
+	}
+	if err := trailingData(dec, body); err != nil {
+		return true, err
+	}
+	path, err := unknownField(raw, reflect.TypeOf(data), "")
+	if err != nil {
+		return true, err
+	}
+	if path != "" {
+		return true, &FieldError{Source: "body", Name: path, Err: ErrUnknownField}
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if useNumber {
+		dec.UseNumber()
+	}
+	return true, dec.Decode(data)
+}
+
+// trailingData rejects data other than whitespace after the JSON value read by the decoder, the same as json.Unmarshal
+func trailingData(dec *json.Decoder, body io.Reader) error {
+	buf := make([]byte, 64)
+	for _, rest := range []io.Reader{dec.Buffered(), body} {
+		for {
+			n, err := rest.Read(buf)
+			for _, c := range buf[:n] {
+				switch c {
+				case ' ', '\t', '\r', '\n':
+					continue
+				}
+				return fmt.Errorf("invalid character %q after top-level value", rune(c))
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unknownField returns the JSON Pointer to the first object key in the JSON value with no matching struct field in the type
+func unknownField(raw json.RawMessage, typ reflect.Type, path string) (string, error) {
+	typ = jsonValueType(typ)
+	if jsonOpaque(typ) {
+		return "", nil
+	}
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Map:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil || object == nil {
+			return "", nil
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := typ
+			if typ.Kind() == reflect.Map {
+				child = typ.Elem()
+			} else if f, ok := jsonFieldType(typ, key); ok {
+				child = f
+			} else {
+				return path + "/" + escapePointer(key), nil
+			}
+			if unknown, err := unknownField(object[key], child, path+"/"+escapePointer(key)); unknown != "" || err != nil {
+				return unknown, err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var array []json.RawMessage
+		if err := json.Unmarshal(raw, &array); err != nil {
+			return "", nil
+		}
+		for i, item := range array {
+			if unknown, err := unknownField(item, typ.Elem(), path+"/"+strconv.Itoa(i)); unknown != "" || err != nil {
+				return unknown, err
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,86 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"strconv"
+)
+
+var (
+	addrType     = reflect.TypeOf(netip.Addr{})
+	addrPortType = reflect.TypeOf(netip.AddrPort{})
+)
+
+// decodeMetadata assigns the request metadata with the name to the field, i.e. the method or remote address
+func decodeMetadata(field reflect.Value, typ reflect.Type, r *http.Request, name string) (bool, error) {
+	var v interface{}
+	switch name {
+	case "method":
+		v = r.Method
+	case "host":
+		v = r.Host
+	case "remoteAddr":
+		if r.RemoteAddr == "" {
+			return false, nil
+		}
+		v = r.RemoteAddr
+		switch jsonValueType(typ) {
+		case addrType:
+			addr, err := remoteAddr(r.RemoteAddr)
+			if err != nil {
+				return true, err
+			}
+			v = addr.Addr()
+		case addrPortType:
+			addr, err := remoteAddr(r.RemoteAddr)
+			if err != nil {
+				return true, err
+			}
+			v = addr
+		}
+	case "url":
+		u := *r.URL
+		u.Scheme = scheme(r)
+		if u.Host == "" {
+			u.Host = r.Host
+		}
+		v = &u
+	case "path":
+		v = r.URL.Path
+	case "scheme":
+		v = scheme(r)
+	case "proto":
+		v = r.Proto
+	case "contentLength":
+		v = strconv.FormatInt(r.ContentLength, 10)
+	default:
+		return false, fmt.Errorf("unknown request value: %s", name)
+	}
+	return true, resolveInterface(field, typ, v)
+}
+
+// remoteAddr parses the remote address of a request, which may not have a port
+func remoteAddr(s string) (netip.AddrPort, error) {
+	addr, err := netip.ParseAddrPort(s)
+	if err == nil {
+		return addr, nil
+	}
+	ip, ipErr := netip.ParseAddr(s)
+	if ipErr != nil {
+		return netip.AddrPort{}, err
+	}
+	return netip.AddrPortFrom(ip, 0), nil
+}
+
+// scheme returns the scheme of the request, https for requests received over TLS
+func scheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	if r.URL.Scheme != "" {
+		return r.URL.Scheme
+	}
+	return "http"
+}
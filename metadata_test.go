@@ -0,0 +1,151 @@
+package request
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode_request(t *testing.T) {
+	type request struct {
+		Method        string         `request:"method"`
+		Host          string         `request:"host"`
+		RemoteAddr    string         `request:"remoteAddr"`
+		AddrPort      netip.AddrPort `request:"remoteAddr"`
+		Addr          netip.Addr     `request:"remoteAddr"`
+		AddrPtr       *netip.Addr    `request:"remoteAddr"`
+		URL           string         `request:"url"`
+		URLValue      url.URL        `request:"url"`
+		URLPtr        *url.URL       `request:"url"`
+		Path          string         `request:"path"`
+		Scheme        string         `request:"scheme"`
+		Proto         string         `request:"proto"`
+		ContentLength int64          `request:"contentLength"`
+		Length        Optional[int]  `request:"contentLength"`
+	}
+	addr := netip.MustParseAddr("192.0.2.1")
+	u, _ := url.Parse("https://example.com/users?limit=5")
+
+	r := httptest.NewRequest(http.MethodPost, "https://example.com/users?limit=5", strings.NewReader(`{}`))
+	r.TLS = &tls.ConnectionState{}
+	var got request
+	if err := Decode(r, &got); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	want := request{
+		Method:        http.MethodPost,
+		Host:          "example.com",
+		RemoteAddr:    "192.0.2.1:1234",
+		AddrPort:      netip.AddrPortFrom(addr, 1234),
+		Addr:          addr,
+		AddrPtr:       &addr,
+		URL:           "https://example.com/users?limit=5",
+		URLValue:      *u,
+		URLPtr:        u,
+		Path:          "/users",
+		Scheme:        "https",
+		Proto:         "HTTP/1.1",
+		ContentLength: 2,
+	}
+	want.Length.Set(2)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecode_requestBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "http://example.com/users", strings.NewReader(`{"Method":"DELETE","Host":"evil.com","name":"adam"}`))
+	r.Header.Set("Content-Type", "application/json")
+	var req struct {
+		Method string `request:"method"`
+		Host   string `request:"host"`
+		Name   string `json:"name"`
+	}
+	if err := Decode(r, &req); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	if req.Method != http.MethodPost || req.Host != "example.com" || req.Name != "adam" {
+		t.Errorf("Decode() = %+v, want the request method and host", req)
+	}
+}
+
+func TestDecode_requestScheme(t *testing.T) {
+	var req struct {
+		Scheme string `request:"scheme"`
+		URL    string `request:"url"`
+	}
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	if err := Decode(r, &req); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	if req.Scheme != "http" || req.URL != "http://example.com/users" {
+		t.Errorf("Decode() = %+v", req)
+	}
+}
+
+func TestDecode_requestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		data interface{}
+	}{
+		{
+			name: "unknown name",
+			data: &struct {
+				Value string `request:"cookie"`
+			}{},
+		},
+		{
+			name: "invalid remote address",
+			addr: "pipe",
+			data: &struct {
+				Addr netip.Addr `request:"remoteAddr"`
+			}{},
+		},
+		{
+			name: "unsupported type",
+			data: &struct {
+				Method int `request:"method"`
+			}{},
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.addr != "" {
+				r.RemoteAddr = tt.addr
+			}
+			err := Decode(r, tt.data)
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Source != "request" {
+				t.Errorf("Decode() error = %v, want request *FieldError", err)
+			}
+		})
+	}
+}
+
+func Test_remoteAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    netip.AddrPort
+		wantErr bool
+	}{
+		{addr: "192.0.2.1:1234", want: netip.MustParseAddrPort("192.0.2.1:1234")},
+		{addr: "[2001:db8::1]:80", want: netip.MustParseAddrPort("[2001:db8::1]:80")},
+		{addr: "192.0.2.1", want: netip.MustParseAddrPort("192.0.2.1:0")},
+		{addr: "@", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := remoteAddr(tt.addr)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("remoteAddr(%q) = %v, %v, want %v", tt.addr, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// contextKey is the context key of a value of the type decoded by Middleware
+type contextKey[T any] struct{}
+
+// Middleware decodes each request into T before calling the next handler, storing the value in the request context
+// to be retrieved with FromContext. Struct types only decode the request body into fields tagged with body, leaving the body
+// untouched for the next handler otherwise. A body that is decoded is restored, so the next handler can read it again.
+// Requests that fail to decode are answered by the error handler of the options, WriteError by default.
+func Middleware[T any](next http.Handler, opts ...Option) http.Handler {
+	d := NewDecoder(opts...)
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	d.taggedBody = true
+	d.restoreBody = d.restoreBody || t.Kind() != reflect.Struct || hasTaggedBody(t)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v T
+		if err := d.Decode(r, &v); err != nil {
+			d.errorHandler(w, r, &statusError{code: statusCode(err, http.StatusBadRequest), err: err})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), v)))
+	})
+}
+
+// NewContext returns a copy of the context holding the value, to be retrieved with FromContext
+func NewContext[T any](ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, contextKey[T]{}, v)
+}
+
+// FromContext returns the value of the type T decoded by Middleware, and whether the context holds one
+func FromContext[T any](ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(contextKey[T]{}).(T)
+	return v, ok
+}
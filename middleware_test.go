@@ -0,0 +1,191 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type tenant struct {
+	ID    string `header:"X-Tenant-ID,required"`
+	Limit int    `query:"limit" default:"10" max:"100"`
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		target     string
+		tenant     string
+		body       string
+		mediaType  string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "decoded",
+			target:     "/?limit=5",
+			tenant:     "acme",
+			wantStatus: http.StatusOK,
+			wantBody:   "acme 5 {\"name\":\"adam\"}",
+		},
+		{
+			name:       "json array body",
+			target:     "/",
+			tenant:     "acme",
+			body:       `[{"name":"adam"}]`,
+			wantStatus: http.StatusOK,
+			wantBody:   `acme 10 [{"name":"adam"}]`,
+		},
+		{
+			name:       "mismatched json body",
+			target:     "/",
+			tenant:     "acme",
+			body:       `{"ID":5,"Limit":"ten"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `acme 10 {"ID":5,"Limit":"ten"}`,
+		},
+		{
+			name:       "csv body",
+			target:     "/",
+			tenant:     "acme",
+			body:       "id,limit\nadam,5\n",
+			mediaType:  "text/csv",
+			wantStatus: http.StatusOK,
+			wantBody:   "acme 10 id,limit\nadam,5\n",
+		},
+		{
+			name:       "missing",
+			target:     "/",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid field ID (header \"X-Tenant-ID\"): missing required value"}` + "\n",
+		},
+		{
+			name:       "invalid",
+			target:     "/?limit=500",
+			tenant:     "acme",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid field Limit (query \"limit\"): must be at most 100"}` + "\n",
+		},
+		{
+			name: "error handler",
+			opts: []Option{WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+			})},
+			target:     "/",
+			wantStatus: http.StatusForbidden,
+			wantBody:   "forbidden\n",
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				v, ok := FromContext[tenant](r.Context())
+				if !ok {
+					t.Fatal("FromContext() missing value")
+				}
+				body, _ := io.ReadAll(r.Body)
+				fmt.Fprintf(w, "%s %d %s", v.ID, v.Limit, body)
+			})
+
+			body, mediaType := `{"name":"adam"}`, "application/json"
+			if tt.body != "" {
+				body = tt.body
+			}
+			if tt.mediaType != "" {
+				mediaType = tt.mediaType
+			}
+			r := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(body))
+			r.Header.Set("Content-Type", mediaType)
+			if tt.tenant != "" {
+				r.Header.Set("X-Tenant-ID", tt.tenant)
+			}
+			rec := httptest.NewRecorder()
+			Middleware[tenant](next, tt.opts...).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Middleware() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("Middleware() body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMiddleware_body(t *testing.T) {
+	type event struct {
+		ID   string `header:"X-Tenant-ID"`
+		User struct {
+			Name string `json:"name"`
+		} `body:"application/json"`
+	}
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "decoded",
+			body:       `{"name":"adam"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `acme adam {"name":"adam"}`,
+		},
+		{
+			name:       "mismatched json body",
+			body:       `{"name":5}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				v, _ := FromContext[event](r.Context())
+				body, _ := io.ReadAll(r.Body)
+				fmt.Fprintf(w, "%s %s %s", v.ID, v.User.Name, body)
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("X-Tenant-ID", "acme")
+			rec := httptest.NewRecorder()
+			Middleware[event](next).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Middleware() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Body.String(); tt.wantBody != "" && got != tt.wantBody {
+				t.Errorf("Middleware() body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMiddleware_options(t *testing.T) {
+	opts := make([]Option, 1, 2)
+	opts[0] = WithMaxBodyBytes(1024)
+	Middleware[tenant](http.NotFoundHandler(), opts...)
+	if extra := opts[:2][1]; extra != nil {
+		t.Error("Middleware() appended to the options")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext[tenant](context.Background()); ok {
+		t.Error("FromContext() found value in empty context")
+	}
+	ctx := NewContext(context.Background(), tenant{ID: "acme"})
+	if v, ok := FromContext[tenant](ctx); !ok || v.ID != "acme" {
+		t.Errorf("FromContext() = %+v, %v", v, ok)
+	}
+	if _, ok := FromContext[*tenant](ctx); ok {
+		t.Error("FromContext() found value of another type")
+	}
+}
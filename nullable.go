@@ -0,0 +1,91 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Nullable is a field value that records whether it was absent, explicitly null, or set to a value in the request.
+// A JSON null, or the value null supplied by any tag, marks the field null.
+type Nullable[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// Set assigns the value and marks it present and not null
+func (n *Nullable[T]) Set(v T) {
+	n.value = v
+	n.set = true
+	n.null = false
+}
+
+// SetNull clears the value and marks it present and null
+func (n *Nullable[T]) SetNull() {
+	var zero T
+	n.value = zero
+	n.set = true
+	n.null = true
+}
+
+// Value returns the value, or the zero value when it was absent or null
+func (n Nullable[T]) Value() T {
+	return n.value
+}
+
+// IsSet reports whether the value was present, either null or set to a value
+func (n Nullable[T]) IsSet() bool {
+	return n.set
+}
+
+// IsNull reports whether the value was present and null
+func (n Nullable[T]) IsNull() bool {
+	return n.null
+}
+
+// Get returns the value and whether it was present and not null
+func (n Nullable[T]) Get() (T, bool) {
+	return n.value, n.set && !n.null
+}
+
+// UnmarshalJSON decodes the value, marking it null for a JSON null
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		n.SetNull()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	n.Set(v)
+	return nil
+}
+
+// MarshalJSON encodes the value, or a JSON null when it was absent or null
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.set || n.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.value)
+}
+
+func (n *Nullable[T]) wrapped() (reflect.Value, bool) {
+	return reflect.ValueOf(&n.value).Elem(), n.set && !n.null
+}
+
+func (n *Nullable[T]) wrap(v reflect.Value) {
+	n.Set(v.Interface().(T))
+}
+
+func (n *Nullable[T]) wrapNull() {
+	n.SetNull()
+}
+
+// nullWrapper is implemented by wrapper field types that can hold an explicit null, such as Nullable
+type nullWrapper interface {
+	wrapper
+	// wrapNull marks the held value present and null
+	wrapNull()
+}
@@ -0,0 +1,73 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNullable(t *testing.T) {
+	type nullables struct {
+		Name  Nullable[string] `json:"name"`
+		Age   Nullable[int]    `json:"age"`
+		Email Nullable[string] `json:"email"`
+		Sort  Nullable[string] `query:"sort"`
+		Tags  Nullable[[]int]  `header:"X-Tags"`
+	}
+	type state struct {
+		set, null bool
+	}
+	r := httptest.NewRequest(http.MethodPatch, "/?sort=null", strings.NewReader(`{"name":null,"age":30}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Tags", "1")
+	r.Header.Add("X-Tags", "2")
+
+	var got nullables
+	if err := Decode(r, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		got  state
+		want state
+	}{
+		{name: "json null", got: state{got.Name.IsSet(), got.Name.IsNull()}, want: state{true, true}},
+		{name: "json value", got: state{got.Age.IsSet(), got.Age.IsNull()}, want: state{true, false}},
+		{name: "json absent", got: state{got.Email.IsSet(), got.Email.IsNull()}, want: state{false, false}},
+		{name: "query null", got: state{got.Sort.IsSet(), got.Sort.IsNull()}, want: state{true, true}},
+		{name: "header value", got: state{got.Tags.IsSet(), got.Tags.IsNull()}, want: state{true, false}},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("Nullable state = %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+	if v, ok := got.Age.Get(); !ok || v != 30 {
+		t.Errorf("Nullable.Get() = %v, %v, want 30, true", v, ok)
+	}
+	if v := got.Tags.Value(); len(v) != 2 || v[0] != 1 || v[1] != 2 {
+		t.Errorf("Nullable.Value() = %v, want [1 2]", v)
+	}
+}
+
+func TestNullable_MarshalJSON(t *testing.T) {
+	var absent, null, value Nullable[string]
+	null.SetNull()
+	value.Set("adam")
+	b, err := json.Marshal(struct {
+		Absent Nullable[string] `json:"absent"`
+		Null   Nullable[string] `json:"null"`
+		Value  Nullable[string] `json:"value"`
+	}{absent, null, value})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"absent":null,"null":null,"value":"adam"}`; string(b) != want {
+		t.Errorf("json.Marshal() = %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,81 @@
+package request
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Optional is a field value that records whether it was present in the request.
+// Optional fields are set by any tag that supplies a value, and by the request body when the JSON key is present.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Set assigns the value and marks it present
+func (o *Optional[T]) Set(v T) {
+	o.value = v
+	o.set = true
+}
+
+// Value returns the value, or the zero value when it was not present
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+// IsSet reports whether the value was present
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// Get returns the value and whether it was present
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// UnmarshalJSON decodes the value and marks it present
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &o.value); err != nil {
+		return err
+	}
+	o.set = true
+	return nil
+}
+
+// MarshalJSON encodes the value
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.value)
+}
+
+func (o *Optional[T]) wrapped() (reflect.Value, bool) {
+	return reflect.ValueOf(&o.value).Elem(), o.set
+}
+
+func (o *Optional[T]) wrap(v reflect.Value) {
+	o.Set(v.Interface().(T))
+}
+
+// wrapper is implemented by field types that hold a decoded value, such as Optional and Nullable
+type wrapper interface {
+	// wrapped returns the settable held value and whether it is present
+	wrapped() (reflect.Value, bool)
+	// wrap assigns the held value and marks it present
+	wrap(v reflect.Value)
+}
+
+// asWrapper returns the wrapper implemented by the field value
+func asWrapper(v reflect.Value) (wrapper, bool) {
+	if v.Kind() != reflect.Struct || !v.CanAddr() || !v.CanInterface() {
+		return nil, false
+	}
+	w, ok := v.Addr().Interface().(wrapper)
+	return w, ok
+}
+
+// isSlice reports whether the field value, or the value held by a wrapper field, is a slice
+func isSlice(v reflect.Value) bool {
+	if w, ok := asWrapper(v); ok {
+		v, _ = w.wrapped()
+	}
+	return v.Kind() == reflect.Slice
+}
@@ -0,0 +1,100 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptional(t *testing.T) {
+	type optionals struct {
+		Name   Optional[string]   `query:"name"`
+		Limit  Optional[int]      `header:"X-Limit"`
+		IDs    Optional[[]string] `query:"id,explode"`
+		Tags   Optional[[]string] `header:"X-Tag"`
+		Sort   Optional[string]   `query:"sort" default:"asc"`
+		State  Optional[string]   `json:"state"`
+		Active Optional[bool]     `json:"active"`
+	}
+	tests := []struct {
+		name    string
+		r       *http.Request
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "absent",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			}(),
+			want: map[string]interface{}{"Sort": "asc"},
+		},
+		{
+			name: "present",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/?name=&id=a&id=b", strings.NewReader(`{"state":"","active":false}`))
+				r.Header.Set("Content-Type", "application/json")
+				r.Header.Set("X-Limit", "0")
+				r.Header.Add("X-Tag", "a")
+				r.Header.Add("X-Tag", "b")
+				return r
+			}(),
+			want: map[string]interface{}{"Name": "", "Limit": 0, "IDs": []string{"a", "b"}, "Tags": []string{"a", "b"}, "Sort": "asc", "State": "", "Active": false},
+		},
+		{
+			name: "invalid",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Set("X-Limit", "many")
+				return r
+			}(),
+			wantErr: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			var got optionals
+			err := Decode(tt.r, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			present := map[string]interface{}{}
+			v := reflect.ValueOf(&got).Elem()
+			for i := 0; i < v.NumField(); i++ {
+				value, set := v.Field(i).Addr().Interface().(wrapper).wrapped()
+				if set {
+					present[v.Type().Field(i).Name] = value.Interface()
+				}
+			}
+			if !reflect.DeepEqual(present, tt.want) {
+				t.Errorf("Decode() present = %v, want %v", present, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptional_JSON(t *testing.T) {
+	var o Optional[int]
+	if err := json.Unmarshal([]byte(`5`), &o); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if v, ok := o.Get(); !ok || v != 5 {
+		t.Errorf("Optional.Get() = %v, %v, want 5, true", v, ok)
+	}
+	b, err := json.Marshal(o)
+	if err != nil || string(b) != "5" {
+		t.Errorf("json.Marshal() = %s, %v, want 5", b, err)
+	}
+	if err := json.Unmarshal([]byte(`"five"`), &o); err == nil {
+		t.Errorf("json.Unmarshal() error = nil, want error")
+	}
+}
@@ -0,0 +1,485 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONPatch is a JSON Patch document, as defined by RFC 6902.
+// Requests with the application/json-patch+json content type are decoded into JSONPatch fields.
+type JSONPatch []PatchOperation
+
+// PatchOperation is a single JSON Patch operation
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Validate checks that each operation is well formed and that its paths exist in the JSON representation of the target's type
+func (p JSONPatch) Validate(target interface{}) error {
+	typ := reflect.TypeOf(target)
+	for i, op := range p {
+		if err := op.validate(typ); err != nil {
+			return fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+		}
+	}
+	return nil
+}
+
+func (op PatchOperation) validate(typ reflect.Type) error {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("%s requires a value", op.Op)
+		}
+	case "move", "copy":
+		if err := validatePointer(typ, op.From); err != nil {
+			return fmt.Errorf("from: %v", err)
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unsupported op: %q", op.Op)
+	}
+	return validatePointer(typ, op.Path)
+}
+
+// Apply applies the operations in order to the JSON representation of the target, which must be a pointer.
+// Nothing is applied when any operation fails.
+func (p JSONPatch) Apply(target interface{}) error {
+	return applyJSON(target, func(doc interface{}) (interface{}, error) {
+		for i, op := range p {
+			var err error
+			if doc, err = op.apply(doc); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+			}
+		}
+		return doc, nil
+	})
+}
+
+func (op PatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%s requires a value", op.Op)
+		}
+		value, err := decodeJSONValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return addPointer(doc, path, value)
+		case "replace":
+			return replacePointer(doc, path, value)
+		}
+		current, err := getPointer(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, value) {
+			return nil, fmt.Errorf("test failed: %s", op.Path)
+		}
+		return doc, nil
+	case "remove":
+		return removePointer(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getPointer(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, fmt.Errorf("cannot move %s into itself", op.From)
+			}
+			if doc, err = removePointer(doc, from); err != nil {
+				return nil, err
+			}
+		} else if value, err = copyJSONValue(value); err != nil {
+			return nil, err
+		}
+		return addPointer(doc, path, value)
+	}
+	return nil, fmt.Errorf("unsupported op: %q", op.Op)
+}
+
+// MergePatch is a JSON Merge Patch document, as defined by RFC 7396.
+// Requests with the application/merge-patch+json content type are decoded into MergePatch fields.
+type MergePatch json.RawMessage
+
+// UnmarshalJSON stores a copy of the patch document
+func (p *MergePatch) UnmarshalJSON(b []byte) error {
+	*p = append((*p)[:0], b...)
+	return nil
+}
+
+// MarshalJSON returns the patch document
+func (p MergePatch) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+// Validate checks that the keys of the patch exist in the JSON representation of the target's type
+func (p MergePatch) Validate(target interface{}) error {
+	patch, err := decodeJSONValue(p)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	if err := validateMerge(reflect.TypeOf(target), patch, ""); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return nil
+}
+
+// Apply merges the patch into the JSON representation of the target, which must be a pointer
+func (p MergePatch) Apply(target interface{}) error {
+	patch, err := decodeJSONValue(p)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return applyJSON(target, func(doc interface{}) (interface{}, error) {
+		return mergeJSON(doc, patch), nil
+	})
+}
+
+// mergeJSON merges the patch into the document following the RFC 7396 algorithm
+func mergeJSON(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]interface{})
+	if !ok {
+		d = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(d, k)
+			continue
+		}
+		d[k] = mergeJSON(d[k], v)
+	}
+	return d
+}
+
+func validateMerge(typ reflect.Type, patch interface{}, path string) error {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	typ = jsonValueType(typ)
+	if jsonOpaque(typ) {
+		return nil
+	}
+	for k, v := range p {
+		var child reflect.Type
+		switch typ.Kind() {
+		case reflect.Struct:
+			if child, ok = jsonFieldType(typ, k); !ok {
+				return fmt.Errorf("unknown field: %s/%s", path, escapePointer(k))
+			}
+		case reflect.Map:
+			child = typ.Elem()
+		default:
+			return fmt.Errorf("not an object: %s", path)
+		}
+		if err := validateMerge(child, v, path+"/"+escapePointer(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyJSON applies the patch function to the JSON representation of the target and decodes the result back into the target
+func applyJSON(target interface{}, patch func(interface{}) (interface{}, error)) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("invalid patch target: %v", reflect.TypeOf(target))
+	}
+	b, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeJSONValue(b)
+	if err != nil {
+		return err
+	}
+	if doc, err = patch(doc); err != nil {
+		return err
+	}
+	if b, err = json.Marshal(doc); err != nil {
+		return err
+	}
+
+	result := reflect.New(v.Elem().Type())
+	result.Elem().Set(v.Elem())
+	if result.Elem().Kind() == reflect.Struct {
+		resetJSONFields(result.Elem())
+	} else {
+		result.Elem().Set(reflect.Zero(result.Elem().Type()))
+	}
+	if err := json.Unmarshal(b, result.Interface()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	v.Elem().Set(result.Elem())
+	return nil
+}
+
+// decodeJSONValue decodes a JSON document into generic values, preserving numbers
+func decodeJSONValue(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func copyJSONValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(b)
+}
+
+// jsonEqual compares generic JSON values, treating numbers by value
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, aErr := a.Float64()
+		bf, bErr := b.Float64()
+		return aErr == nil && bErr == nil && af == bf
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// parsePointer splits a JSON Pointer, as defined by RFC 6901, into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer: %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// validatePointer checks the JSON Pointer references a location in the JSON representation of the type
+func validatePointer(typ reflect.Type, pointer string) error {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		typ = jsonValueType(typ)
+		if jsonOpaque(typ) {
+			return nil
+		}
+		switch typ.Kind() {
+		case reflect.Struct:
+			var ok bool
+			if typ, ok = jsonFieldType(typ, token); !ok {
+				return fmt.Errorf("unknown field: %s", pointer)
+			}
+		case reflect.Map:
+			typ = typ.Elem()
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(token); err != nil && token != "-" {
+				return fmt.Errorf("invalid index: %s", pointer)
+			}
+			typ = typ.Elem()
+		default:
+			return fmt.Errorf("path not found: %s", pointer)
+		}
+	}
+	return nil
+}
+
+func getPointer(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %s", token)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("path not found: %s", token)
+		}
+	}
+	return doc, nil
+}
+
+func addPointer(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updatePointer(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			i, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		return nil, fmt.Errorf("path not found: %s", token)
+	})
+}
+
+func replacePointer(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updatePointer(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("path not found: %s", token)
+			}
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			node[i] = value
+			return node, nil
+		}
+		return nil, fmt.Errorf("path not found: %s", token)
+	})
+}
+
+func removePointer(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the document root")
+	}
+	return updatePointer(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("path not found: %s", token)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("path not found: %s", token)
+	})
+}
+
+// updatePointer walks to the parent of the last reference token and replaces it with the result of the update
+func updatePointer(doc interface{}, path []string, update func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("path not found: %s", path[0])
+		}
+		child, err := updatePointer(child, path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		node[path[0]] = child
+		return node, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := updatePointer(node[i], path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = child
+		return node, nil
+	}
+	return nil, fmt.Errorf("path not found: %s", path[0])
+}
+
+// arrayIndex parses an array index reference token no greater than max
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid index: %s", token)
+	}
+	return i, nil
+}
@@ -0,0 +1,171 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type patchAddress struct {
+	City string `json:"city"`
+}
+
+type patchUser struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age,omitempty"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Address *patchAddress     `json:"address,omitempty"`
+	Secret  string            `json:"-"`
+}
+
+func TestJSONPatch_Apply(t *testing.T) {
+	user := func() patchUser {
+		return patchUser{Name: "adam", Age: 30, Tags: []string{"a", "b"}, Address: &patchAddress{City: "Denver"}, Secret: "keep"}
+	}
+	tests := []struct {
+		name    string
+		patch   string
+		want    patchUser
+		wantErr bool
+	}{
+		{
+			name:  "add and replace",
+			patch: `[{"op":"add","path":"/tags/1","value":"c"},{"op":"add","path":"/tags/-","value":"d"},{"op":"replace","path":"/name","value":"eve"}]`,
+			want:  patchUser{Name: "eve", Age: 30, Tags: []string{"a", "c", "b", "d"}, Address: &patchAddress{City: "Denver"}, Secret: "keep"},
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/age"},{"op":"remove","path":"/tags/0"},{"op":"remove","path":"/address"}]`,
+			want:  patchUser{Name: "adam", Tags: []string{"b"}, Secret: "keep"},
+		},
+		{
+			name:  "move and copy",
+			patch: `[{"op":"copy","from":"/tags/0","path":"/tags/-"},{"op":"move","from":"/address/city","path":"/name"}]`,
+			want:  patchUser{Name: "Denver", Age: 30, Tags: []string{"a", "b", "a"}, Address: &patchAddress{}, Secret: "keep"},
+		},
+		{
+			name:  "test",
+			patch: `[{"op":"test","path":"/age","value":30.0},{"op":"add","path":"/labels","value":{"a~b/c":"d"}}]`,
+			want:  patchUser{Name: "adam", Age: 30, Tags: []string{"a", "b"}, Labels: map[string]string{"a~b/c": "d"}, Address: &patchAddress{City: "Denver"}, Secret: "keep"},
+		},
+		{
+			name:    "failed test",
+			patch:   `[{"op":"replace","path":"/name","value":"eve"},{"op":"test","path":"/age","value":31}]`,
+			want:    user(),
+			wantErr: true,
+		},
+		{
+			name:    "missing path",
+			patch:   `[{"op":"replace","path":"/labels/a","value":"b"}]`,
+			want:    user(),
+			wantErr: true,
+		},
+		{
+			name:    "invalid index",
+			patch:   `[{"op":"add","path":"/tags/5","value":"c"}]`,
+			want:    user(),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported op",
+			patch:   `[{"op":"merge","path":"/name"}]`,
+			want:    user(),
+			wantErr: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			var patch JSONPatch
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			got := user()
+			err := patch.Apply(&got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("JSONPatch.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPatch) {
+				t.Errorf("JSONPatch.Apply() error = %v, want ErrInvalidPatch", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JSONPatch.Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPatch_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   JSONPatch
+		wantErr bool
+	}{
+		{name: "valid", patch: JSONPatch{{Op: "replace", Path: "/address/city", Value: json.RawMessage(`"Denver"`)}, {Op: "add", Path: "/tags/-", Value: json.RawMessage(`"a"`)}, {Op: "remove", Path: "/labels/any"}}},
+		{name: "unknown field", patch: JSONPatch{{Op: "remove", Path: "/email"}}, wantErr: true},
+		{name: "ignored field", patch: JSONPatch{{Op: "remove", Path: "/Secret"}}, wantErr: true},
+		{name: "missing value", patch: JSONPatch{{Op: "add", Path: "/name"}}, wantErr: true},
+		{name: "invalid from", patch: JSONPatch{{Op: "move", From: "/email", Path: "/name"}}, wantErr: true},
+		{name: "invalid pointer", patch: JSONPatch{{Op: "remove", Path: "name"}}, wantErr: true},
+		{name: "invalid index", patch: JSONPatch{{Op: "remove", Path: "/tags/first"}}, wantErr: true},
+		{name: "scalar path", patch: JSONPatch{{Op: "remove", Path: "/name/first"}}, wantErr: true},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.patch.Validate(&patchUser{}); (err != nil) != tt.wantErr {
+				t.Errorf("JSONPatch.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		patch       string
+		want        patchUser
+		wantInvalid bool
+	}{
+		{
+			name:  "merge",
+			patch: `{"name":"eve","age":null,"address":{"city":"Boulder"},"labels":{"a":"b"}}`,
+			want:  patchUser{Name: "eve", Tags: []string{"a"}, Labels: map[string]string{"a": "b"}, Address: &patchAddress{City: "Boulder"}, Secret: "keep"},
+		},
+		{
+			name:  "remove object",
+			patch: `{"address":null,"tags":["c"]}`,
+			want:  patchUser{Name: "adam", Age: 30, Tags: []string{"c"}, Secret: "keep"},
+		},
+		{
+			name:        "unknown field",
+			patch:       `{"address":{"zip":"80202"}}`,
+			want:        patchUser{Name: "adam", Age: 30, Tags: []string{"a"}, Address: &patchAddress{City: "Denver"}, Secret: "keep"},
+			wantInvalid: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			var patch MergePatch
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			got := patchUser{Name: "adam", Age: 30, Tags: []string{"a"}, Address: &patchAddress{City: "Denver"}, Secret: "keep"}
+			if err := patch.Validate(&got); (err != nil) != tt.wantInvalid {
+				t.Fatalf("MergePatch.Validate() error = %v, wantErr %v", err, tt.wantInvalid)
+			}
+			if tt.wantInvalid {
+				return
+			}
+			if err := patch.Apply(&got); err != nil {
+				t.Fatalf("MergePatch.Apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergePatch.Apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
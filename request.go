@@ -1,9 +1,11 @@
 package request
 
 import (
-	"encoding/json"
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -12,151 +14,356 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// Decode an HTTP request into the provided struct
+// Decode an HTTP request into the provided pointer.
+// Structs are decoded using their field tags, while other types are decoded from the request body.
 func Decode(r *http.Request, data interface{}) error {
-	typ := reflect.TypeOf(data)
-	if typ == nil {
-		return fmt.Errorf("invalid decode type: nil")
-	}
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
+	return defaultDecoder.Decode(r, data)
+}
+
+func (d *Decoder) decodeRequest(r *http.Request, t reflect.Type, data interface{}) error {
+	if d.restoreBody || (t.Kind() == reflect.Struct && hasRawBody(t)) {
+		if err := d.bufferBody(r); err != nil {
+			return err
+		}
 	}
-	if typ.Kind() != reflect.Struct {
-		return fmt.Errorf("invalid decode type: %v", typ.Kind())
+	if t.Kind() != reflect.Struct {
+		if _, err := d.decodeBody(r, data, "", ""); err != nil {
+			return err
+		}
+		return validateNested(r, reflect.ValueOf(data).Elem(), "", nil)
 	}
 
-	return decodeRequest(r, typ, data)
-}
-
-func decodeRequest(r *http.Request, t reflect.Type, data interface{}) error {
-	body, err := decodeStruct(r, t, data)
+	absent := absentFields{}
+	trusted := trustedFields{}
+	body, err := d.decodeStruct(r, t, data, "", absent, &trusted)
 	if err != nil {
 		return err
 	}
-	if !body {
-		err := decodeBody(r, data)
+	if !body && !d.taggedBody {
+		trusted.hide()
+		_, err := d.decodeBody(r, data, "", "")
+		trusted.restore()
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return validateStruct(r, reflect.ValueOf(data).Elem(), "", absent)
 }
 
-func decodeStruct(r *http.Request, t reflect.Type, data interface{}) (bool, error) {
+// sourceTags are the struct tags that assign field values from the request, in order of precedence
+var sourceTags = []string{"query", "path", "header", "ctx", "request", "status", "body"}
+
+// decodeStruct assigns the struct fields from the request by their source tags, recording the tagged fields the request did not supply as absent,
+// and the fields assigned by trusted tags to hide from the body
+func (d *Decoder) decodeStruct(r *http.Request, t reflect.Type, data interface{}, path string, absent absentFields, trusted *trustedFields) (bool, error) {
 	query := r.URL.Query()
 	vars := mux.Vars(r)
+	v := reflect.ValueOf(data).Elem()
 	body := false
-	for i := 0; i < t.NumField(); i++ {
+	for i, info := range typeInfo(t).fields {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
 		typ := t.Field(i)
-		field := reflect.ValueOf(data).Elem().Field(i)
+		name := fieldPath(path, typ.Name)
 
-		if typ.Type.Kind() == reflect.Struct {
-			var err error
-			if body, err = decodeStruct(r, typ.Type, field.Addr().Interface()); err != nil {
+		if info.nested {
+			nested, err := d.decodeStruct(r, typ.Type, field.Addr().Interface(), name, absent, trusted)
+			body = body || nested
+			if err != nil {
 				return body, err
 			}
 		}
 
-		if queryTag := typ.Tag.Get("query"); queryTag != "" {
-			if err := decodeQuery(field, typ.Type, query, queryTag); err != nil {
-				return body, err
+		var found bool
+		var required *FieldError
+		var allowEmpty bool
+		for _, tag := range info.sources {
+			source, key, opts := tag.source, tag.name, tag.opts
+
+			var ok bool
+			var err error
+			switch source {
+			case "query":
+				ok, err = decodeQuery(field, typ.Type, query, key, opts)
+			case "path":
+				ok, err = decodePath(field, typ.Type, vars, key)
+			case "header":
+				ok, err = decodeHeader(field, typ.Type, r.Header, key)
+			case "ctx":
+				ok, err = d.decodeContext(field, typ.Type, r.Context(), key)
+			case "request":
+				ok, err = decodeMetadata(field, typ.Type, r, key)
+			case "status":
+				ok, err = decodeStatus(field, typ.Type, r)
+			case "body":
+				body = true
+				trusted.hide()
+				ok, err = d.decodeBody(r, field.Addr().Interface(), key, opts)
+				trusted.restore()
 			}
-		}
+			if err != nil {
+				var fieldErr *FieldError
+				if errors.As(err, &fieldErr) && fieldErr.Field == "" {
+					fieldErr.Field = name
+					return body, err
+				}
+				if fieldErr != nil && source == "body" {
+					fieldErr.Field = fieldPath(name, fieldErr.Field)
+					return body, err
+				}
+				return body, &FieldError{Field: name, Source: source, Name: key, Err: err}
+			}
+			found = found || ok
 
-		if pathTag := typ.Tag.Get("path"); pathTag != "" {
-			if err := decodePath(field, typ.Type, vars, pathTag); err != nil {
-				return body, err
+			if required == nil && opts.Contains("required") {
+				required = &FieldError{Field: name, Source: source, Name: key, Err: ErrMissing}
+				allowEmpty = opts.Contains("allowEmpty")
 			}
 		}
 
-		if headerTag := typ.Tag.Get("header"); headerTag != "" {
-			if err := decodeHeader(field, typ.Type, r.Header, headerTag); err != nil {
-				return body, err
+		if info.hasDefault && !found {
+			if err := decodeDefault(field, typ.Type, info.defaultTag); err != nil {
+				return body, &FieldError{Field: name, Source: "default", Name: info.defaultTag, Err: err}
 			}
+			found = true
 		}
 
-		bodyTag := typ.Tag.Get("body")
-		if bodyTag != "" {
-			body = true
-			if err := decodeBody(r, field.Addr().Interface()); err != nil {
-				return body, err
-			}
+		if required != nil && (!found || (!allowEmpty && isEmpty(field))) {
+			return body, required
+		}
+		if len(info.sources) > 0 && !found {
+			absent.add(field)
+		}
+		if info.trusted {
+			trusted.add(field)
 		}
 	}
 	return body, nil
 }
 
-func decodeQuery(field reflect.Value, typ reflect.Type, query url.Values, tag string) error {
-	parts := strings.Split(tag, ",")
-	if query.Has(parts[0]) {
-		if field.Kind() == reflect.Slice {
-			var explode bool
-			for _, p := range parts[1:] {
-				if p == "explode" {
-					explode = true
-				}
-			}
+// fieldPath joins a struct field name onto the path of its parent struct
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
 
-			var value []string
-			if explode {
-				value = query[parts[0]]
-			} else {
-				value = strings.Split(query.Get(parts[0]), ",")
+// isEmpty reports whether the value is an empty string, or a slice or map with no non empty values
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Map:
+		return v.Len() == 0
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if !isEmpty(v.Index(i)) {
+				return false
 			}
+		}
+		return true
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil() || isEmpty(v.Elem())
+	case reflect.Struct:
+		if w, ok := asWrapper(v); ok {
+			value, set := w.wrapped()
+			return !set || isEmpty(value)
+		}
+	}
+	return false
+}
 
-			if err := resolveValues(field, typ, value); err != nil {
-				return err
-			}
-			return nil
+func decodeQuery(field reflect.Value, typ reflect.Type, query url.Values, name string, opts tagOptions) (bool, error) {
+	if !query.Has(name) {
+		return false, nil
+	}
+	if isSlice(field) {
+		var value []string
+		if opts.Contains("explode") {
+			value = query[name]
+		} else {
+			value = strings.Split(query.Get(name), ",")
 		}
-		if err := resolveValue(field, typ, query.Get(parts[0])); err != nil {
-			return err
+
+		if err := resolveValues(field, typ, value); err != nil {
+			return true, err
 		}
+		return true, nil
+	}
+	if err := resolveValue(field, typ, query.Get(name)); err != nil {
+		return true, err
 	}
-	return nil
+	return true, nil
 }
 
-func decodePath(field reflect.Value, typ reflect.Type, vars map[string]string, tag string) error {
-	if path, ok := vars[tag]; ok {
-		if err := resolveValue(field, typ, path); err != nil {
-			return err
+func decodePath(field reflect.Value, typ reflect.Type, vars map[string]string, name string) (bool, error) {
+	path, ok := vars[name]
+	if !ok {
+		return false, nil
+	}
+	if err := resolveValue(field, typ, path); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+func decodeHeader(field reflect.Value, typ reflect.Type, header http.Header, name string) (bool, error) {
+	if isSlice(field) {
+		values := header.Values(name)
+		if len(values) == 0 {
+			return false, nil
 		}
+		return true, resolveValues(field, typ, values)
+	}
+	if header.Get(name) == "" {
+		return false, nil
+	}
+	if err := resolveValue(field, typ, header.Get(name)); err != nil {
+		return true, err
 	}
-	return nil
+	return true, nil
 }
 
-func decodeHeader(field reflect.Value, typ reflect.Type, header http.Header, tag string) error {
-	if field.Kind() == reflect.Slice {
-		if err := resolveValues(field, typ, header.Values(tag)); err != nil {
-			return err
+// decodeDefault assigns the default tag value, splitting slice values with commas
+func decodeDefault(field reflect.Value, typ reflect.Type, value string) error {
+	if isSlice(field) {
+		return resolveValues(field, typ, strings.Split(value, ","))
+	}
+	return resolveValue(field, typ, value)
+}
+
+// decodeBody streams the request body into data, reporting whether the request had a body.
+// Body fields with the raw name, or of a string or byte slice type, are assigned the body as it is,
+// and io.Reader fields are assigned the body to be streamed once decoded.
+func (d *Decoder) decodeBody(r *http.Request, data interface{}, name string, opts tagOptions) (bool, error) {
+	if r.Body == nil {
+		return false, nil
+	}
+	body, closeBody, err := d.openBody(r)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, bodyError(err)
+	}
+
+	if s, ok := data.(streamer); ok {
+		reader := bufio.NewReader(body)
+		if _, err := reader.Peek(1); err != nil {
+			closeBody()
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, bodyError(err)
 		}
-		return nil
+		s.open(r, reader, closeBody, d.useNumber || opts.Contains("useNumber"), d.strict || opts.Contains("strict"))
+		return true, nil
 	}
-	if header.Get(tag) != "" {
-		if err := resolveValue(field, typ, header.Get(tag)); err != nil {
-			return err
+
+	typ := reflect.TypeOf(data).Elem()
+	if name != "" && (typ == readerType || typ == readCloserType) {
+		return decodeStreamBody(body, closeBody, data)
+	}
+	defer closeBody()
+
+	if name == "raw" || (name != "" && isRawType(typ)) {
+		return decodeRawBody(body, data)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch contentType {
+	case "application/json", "application/json-patch+json", "application/merge-patch+json":
+		if disc, ok := d.discriminators[typ]; ok {
+			found, err := d.decodeDiscriminated(r, body, data, disc, opts)
+			return found, bodyError(err)
 		}
+		found, err := d.decodeJSON(body, data, opts)
+		return found, bodyError(err)
+	case "text/csv":
+		return decodeCSV(body, data)
+	}
+
+	n, err := io.ReadFull(body, make([]byte, 1))
+	if err != nil && err != io.EOF {
+		return false, bodyError(err)
 	}
-	return nil
+	return n > 0, nil
 }
 
-func decodeBody(r *http.Request, data interface{}) error {
-	b, err := io.ReadAll(r.Body)
+// decodeRawBody assigns the body to a string or byte slice, such as json.RawMessage
+func decodeRawBody(body io.Reader, data interface{}) (bool, error) {
+	v := reflect.ValueOf(data).Elem()
+	if !isRawType(v.Type()) {
+		return false, fmt.Errorf("unsupported raw body type: %v", v.Type())
+	}
+	b, err := io.ReadAll(body)
 	if err != nil {
-		return err
+		return false, bodyError(err)
 	}
-	defer r.Body.Close()
 	if len(b) == 0 {
-		return nil
+		return false, nil
 	}
+	if v.Kind() == reflect.String {
+		v.SetString(string(b))
+	} else {
+		v.SetBytes(b)
+	}
+	return true, nil
+}
 
-	switch r.Header.Get("Content-Type") {
-	case "application/json":
-		err := json.Unmarshal(b, &data)
-		if err != nil {
-			return err
+// decodeStreamBody assigns the body to an io.Reader or io.ReadCloser, closing the body once the reader is closed.
+// Empty bodies are assigned http.NoBody.
+func decodeStreamBody(body io.Reader, closeBody func(), data interface{}) (bool, error) {
+	field := reflect.ValueOf(data).Elem()
+	reader := bufio.NewReader(body)
+	if _, err := reader.Peek(1); err != nil {
+		closeBody()
+		if err == io.EOF {
+			field.Set(reflect.ValueOf(http.NoBody))
+			return false, nil
+		}
+		return false, bodyError(err)
+	}
+	field.Set(reflect.ValueOf(&streamBody{Reader: reader, close: closeBody}))
+	return true, nil
+}
+
+// openBody returns the request body limited to the maximum size and decompressed.
+// The returned func closes the request body, or resets a buffered body to be read again.
+func (d *Decoder) openBody(r *http.Request) (io.Reader, func(), error) {
+	var body io.Reader = r.Body
+	buffered, isBuffered := r.Body.(*bufferedBody)
+	if !isBuffered && d.maxBodyBytes > 0 {
+		if r.ContentLength > d.maxBodyBytes {
+			r.Body.Close()
+			return nil, nil, ErrBodyTooLarge
+		}
+		body = http.MaxBytesReader(nil, r.Body, d.maxBodyBytes)
+	}
+
+	closeBody := func() {
+		r.Body.Close()
+		if isBuffered {
+			setBufferedBody(r, buffered.b)
 		}
 	}
+	body, closeDecompressed, err := d.decompress(body, r.Header.Get("Content-Encoding"))
+	if err != nil {
+		closeBody()
+		return nil, nil, err
+	}
+	return body, func() {
+		closeDecompressed()
+		closeBody()
+	}, nil
+}
 
-	return nil
+// bodyError maps errors reading the request body to the package's errors
+func bodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrBodyTooLarge
+	}
+	return err
 }
@@ -1,11 +1,13 @@
 package request
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func Test_decodeBody(t *testing.T) {
@@ -57,7 +59,7 @@ func Test_decodeBody(t *testing.T) {
 	for i := range tests {
 		tt := tests[i]
 		t.Run(tt.name, func(t *testing.T) {
-			if err := decodeBody(tt.r, tt.data); (err != nil) != tt.wantErr {
+			if _, err := NewDecoder().decodeBody(tt.r, tt.data, "", ""); (err != nil) != tt.wantErr {
 				t.Errorf("decodeBody() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -67,3 +69,195 @@ func Test_decodeBody(t *testing.T) {
 		})
 	}
 }
+
+func Test_decodeStruct_required(t *testing.T) {
+	tests := []struct {
+		name      string
+		r         *http.Request
+		data      interface{}
+		wantField string
+	}{
+		{
+			name: "present query",
+			r:    httptest.NewRequest(http.MethodGet, "/?id=5", nil),
+			data: &struct {
+				ID int `query:"id,required"`
+			}{},
+		},
+		{
+			name: "missing query",
+			r:    httptest.NewRequest(http.MethodGet, "/", nil),
+			data: &struct {
+				ID int `query:"id,required"`
+			}{},
+			wantField: "ID",
+		},
+		{
+			name: "empty query",
+			r:    httptest.NewRequest(http.MethodGet, "/?id=", nil),
+			data: &struct {
+				ID string `query:"id,required"`
+			}{},
+			wantField: "ID",
+		},
+		{
+			name: "empty query allowed",
+			r:    httptest.NewRequest(http.MethodGet, "/?id=", nil),
+			data: &struct {
+				ID string `query:"id,required,allowEmpty"`
+			}{},
+		},
+		{
+			name: "empty query slice",
+			r:    httptest.NewRequest(http.MethodGet, "/?id=", nil),
+			data: &struct {
+				IDs []string `query:"id,required"`
+			}{},
+			wantField: "IDs",
+		},
+		{
+			name: "missing header",
+			r:    httptest.NewRequest(http.MethodGet, "/", nil),
+			data: &struct {
+				Tenant string `header:"X-Tenant,required"`
+			}{},
+			wantField: "Tenant",
+		},
+		{
+			name: "missing path",
+			r:    httptest.NewRequest(http.MethodGet, "/", nil),
+			data: &struct {
+				User string `path:"user,required"`
+			}{},
+			wantField: "User",
+		},
+		{
+			name: "missing nested header",
+			r:    httptest.NewRequest(http.MethodGet, "/", nil),
+			data: &struct {
+				Auth struct {
+					Token string `header:"Authorization,required"`
+				}
+			}{},
+			wantField: "Auth.Token",
+		},
+		{
+			name: "missing body",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", nil)
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			}(),
+			data: &struct {
+				Items []string `body:"application/json,required"`
+			}{},
+			wantField: "Items",
+		},
+		{
+			name: "empty body array",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[]`))
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			}(),
+			data: &struct {
+				Items []string `body:"application/json,required"`
+			}{},
+			wantField: "Items",
+		},
+		{
+			name: "empty body array allowed",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[]`))
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			}(),
+			data: &struct {
+				Items []string `body:"application/json,required,allowEmpty"`
+			}{},
+		},
+		{
+			name: "fallback source present",
+			r:    httptest.NewRequest(http.MethodGet, "/?id=5", nil),
+			data: &struct {
+				ID int `header:"X-ID,required" query:"id"`
+			}{},
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder().decodeStruct(tt.r, reflect.TypeOf(tt.data).Elem(), tt.data, "", nil, nil)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("decodeStruct() error = %v, want nil", err)
+				}
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || !errors.Is(err, ErrMissing) {
+				t.Fatalf("decodeStruct() error = %v, want missing field error", err)
+			}
+			if fieldErr.Field != tt.wantField {
+				t.Errorf("decodeStruct() field = %v, want %v", fieldErr.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func Test_decodeStruct_default(t *testing.T) {
+	type defaults struct {
+		Limit   int           `query:"limit" default:"20"`
+		Sort    []string      `query:"sort" default:"name,age"`
+		Timeout time.Duration `header:"X-Timeout" default:"5s"`
+		Tags    []string      `header:"X-Tag"`
+		Active  *bool         `query:"active" default:"true"`
+		State   string        `json:"state" default:"idle"`
+	}
+	active := true
+	tests := []struct {
+		name    string
+		r       *http.Request
+		want    defaults
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			r:    httptest.NewRequest(http.MethodGet, "/", nil),
+			want: defaults{Limit: 20, Sort: []string{"name", "age"}, Timeout: 5 * time.Second, Active: &active, State: "idle"},
+		},
+		{
+			name: "supplied",
+			r: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/?limit=5&sort=id&active=false", nil)
+				r.Header.Set("X-Timeout", "1m")
+				r.Header.Set("X-Tag", "a")
+				return r
+			}(),
+			want: defaults{Limit: 5, Sort: []string{"id"}, Timeout: time.Minute, Tags: []string{"a"}, Active: new(bool), State: "idle"},
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			var got defaults
+			if _, err := NewDecoder().decodeStruct(tt.r, reflect.TypeOf(got), &got, "", nil, nil); (err != nil) != tt.wantErr {
+				t.Errorf("decodeStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeStruct() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeStruct_defaultFailure(t *testing.T) {
+	var got struct {
+		Limit int `query:"limit" default:"many"`
+	}
+	_, err := NewDecoder().decodeStruct(httptest.NewRequest(http.MethodGet, "/", nil), reflect.TypeOf(got), &got, "", nil, nil)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Source != "default" {
+		t.Errorf("decodeStruct() error = %v, want default field error", err)
+	}
+}
@@ -2,13 +2,28 @@ package request
 
 import (
 	"fmt"
+	"net/netip"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // resolveValues iterates over string values to resolve a slice value on the field
 func resolveValues(field reflect.Value, typ reflect.Type, values []string) error {
+	if w, ok := asWrapper(field); ok {
+		if n, ok := w.(nullWrapper); ok && len(values) == 1 && values[0] == "null" {
+			n.wrapNull()
+			return nil
+		}
+		v, _ := w.wrapped()
+		v = reflect.New(v.Type()).Elem()
+		if err := resolveValues(v, v.Type(), values); err != nil {
+			return err
+		}
+		w.wrap(v)
+		return nil
+	}
 	r := reflect.MakeSlice(typ, len(values), len(values))
 	for i, value := range values {
 		if err := resolveValue(r.Index(i), typ, value); err != nil {
@@ -21,6 +36,19 @@ func resolveValues(field reflect.Value, typ reflect.Type, values []string) error
 
 // resolveValue resolves and sets the string value to appropriate type on the field
 func resolveValue(field reflect.Value, typ reflect.Type, value string) error {
+	if w, ok := asWrapper(field); ok {
+		if n, ok := w.(nullWrapper); ok && value == "null" {
+			n.wrapNull()
+			return nil
+		}
+		v, _ := w.wrapped()
+		v = reflect.New(v.Type()).Elem()
+		if err := resolveValue(v, v.Type(), value); err != nil {
+			return err
+		}
+		w.wrap(v)
+		return nil
+	}
 	if field.Kind() == reflect.Pointer {
 		v, err := resolve(reflect.New(typ.Elem()).Elem().Interface(), value)
 		if err != nil {
@@ -39,6 +67,59 @@ func resolveValue(field reflect.Value, typ reflect.Type, value string) error {
 	return nil
 }
 
+// resolveInterface assigns the value to the field. Values of other types than the field
+// are converted from their string form, i.e. a fmt.Stringer.
+func resolveInterface(field reflect.Value, typ reflect.Type, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if assignValue(field, value) || (value.Kind() == reflect.Pointer && !value.IsNil() && assignValue(field, value.Elem())) {
+		return nil
+	}
+
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case fmt.Stringer:
+		s = t.String()
+	default:
+		var err error
+		if s, err = format(v); err != nil {
+			return fmt.Errorf("cannot assign %T to %v", v, typ)
+		}
+	}
+	if isSlice(field) {
+		return resolveValues(field, typ, strings.Split(s, ","))
+	}
+	return resolveValue(field, typ, s)
+}
+
+// assignValue assigns the value to the field, the value held by a wrapper field, or the value pointed to by a pointer field,
+// reporting whether the value is assignable
+func assignValue(field, value reflect.Value) bool {
+	if value.Type().AssignableTo(field.Type()) {
+		field.Set(value)
+		return true
+	}
+	if w, ok := asWrapper(field); ok {
+		v, _ := w.wrapped()
+		v = reflect.New(v.Type()).Elem()
+		if !assignValue(v, value) {
+			return false
+		}
+		w.wrap(v)
+		return true
+	}
+	if field.Kind() == reflect.Pointer {
+		v := reflect.New(field.Type().Elem())
+		if !assignValue(v.Elem(), value) {
+			return false
+		}
+		field.Set(v)
+		return true
+	}
+	return false
+}
+
 // resolve the string value to the proper type and return the value
 func resolve(t interface{}, v string) (interface{}, error) {
 	switch t.(type) {
@@ -50,6 +131,10 @@ func resolve(t interface{}, v string) (interface{}, error) {
 		return time.Parse(time.RFC3339, v)
 	case time.Duration:
 		return time.ParseDuration(v)
+	case netip.Addr:
+		return netip.ParseAddr(v)
+	case netip.AddrPort:
+		return netip.ParseAddrPort(v)
 	case int:
 		i, err := strconv.ParseInt(v, 10, 32)
 		return int(i), err
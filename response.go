@@ -0,0 +1,51 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// statusKey is the context key of the response status code decoded by status tags
+type statusKey struct{}
+
+// DecodeResponse decodes an HTTP response into the provided pointer.
+// Structs are decoded from header tags, status tags and the response body, while other types are decoded from the response body.
+// The response body is not closed.
+func DecodeResponse(resp *http.Response, data interface{}) error {
+	return defaultDecoder.DecodeResponse(resp, data)
+}
+
+// DecodeResponse decodes an HTTP response into the provided pointer, the same as a request with the response headers and body
+func (d *Decoder) DecodeResponse(resp *http.Response, data interface{}) error {
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+	r := &http.Request{
+		Method:        http.MethodGet,
+		URL:           &url.URL{},
+		Header:        resp.Header,
+		Body:          resp.Body,
+		ContentLength: resp.ContentLength,
+	}
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	r = r.WithContext(context.WithValue(ctx, statusKey{}, resp.StatusCode))
+	return d.Decode(r, data)
+}
+
+// decodeStatus assigns the status code of a decoded response
+func decodeStatus(field reflect.Value, typ reflect.Type, r *http.Request) (bool, error) {
+	status, ok := r.Context().Value(statusKey{}).(int)
+	if !ok {
+		return false, nil
+	}
+	if err := resolveValue(field, typ, strconv.Itoa(status)); err != nil {
+		return true, err
+	}
+	return true, nil
+}
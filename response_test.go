@@ -0,0 +1,105 @@
+package request
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	type response struct {
+		Status     int           `status:"code"`
+		ETag       string        `header:"ETag"`
+		RetryAfter time.Duration `header:"Retry-After"`
+		Links      []string      `header:"Link"`
+		ID         string        `json:"id"`
+	}
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		data    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "headers and body",
+			resp: &http.Response{
+				StatusCode: http.StatusCreated,
+				Header: http.Header{
+					"Content-Type": {"application/json"},
+					"Etag":         {`"v1"`},
+					"Retry-After":  {"5s"},
+					"Link":         {`<https://a>; rel="next"`, `<https://b>; rel="prev"`},
+				},
+				Body: io.NopCloser(strings.NewReader(`{"id":"1"}`)),
+			},
+			data: &response{},
+			want: &response{
+				Status:     http.StatusCreated,
+				ETag:       `"v1"`,
+				RetryAfter: 5 * time.Second,
+				Links:      []string{`<https://a>; rel="next"`, `<https://b>; rel="prev"`},
+				ID:         "1",
+			},
+		},
+		{
+			name: "no body",
+			resp: &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody},
+			data: &response{},
+			want: &response{Status: http.StatusNoContent},
+		},
+		{
+			name: "status text",
+			resp: &http.Response{StatusCode: http.StatusOK},
+			data: &struct {
+				Status string `status:"code"`
+			}{},
+			want: &struct {
+				Status string `status:"code"`
+			}{Status: "200"},
+		},
+		{
+			name: "non struct",
+			resp: &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`["a","b"]`)),
+			},
+			data: &[]string{},
+			want: &[]string{"a", "b"},
+		},
+		{
+			name: "invalid header",
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"soon"}},
+			},
+			data:    &response{},
+			want:    &response{},
+			wantErr: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeResponse(tt.resp, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var fieldErr *FieldError
+				if !errors.As(err, &fieldErr) || fieldErr.Source != "header" {
+					t.Errorf("DecodeResponse() error = %v, want header *FieldError", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(tt.data, tt.want) {
+				t.Errorf("DecodeResponse() = %+v, want %+v", tt.data, tt.want)
+			}
+		})
+	}
+}
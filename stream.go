@@ -0,0 +1,111 @@
+package request
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// Stream decodes a request body of newline delimited JSON values, i.e. application/x-ndjson or application/jsonl, one line at a time.
+// Each value is validated as it is decoded. Streams are read once the request is decoded, and closed once every line is read.
+type Stream[T any] struct {
+	r         *http.Request
+	reader    *bufio.Reader
+	close     func()
+	useNumber bool
+	strict    bool
+	value     T
+	line      int
+	err       error
+}
+
+// Next decodes the next line into the stream's value, reporting false once there are no more lines or a line fails to decode
+func (s *Stream[T]) Next() bool {
+	if s.reader == nil || s.err != nil {
+		return false
+	}
+	for {
+		b, err := s.reader.ReadBytes('\n')
+		if len(b) > 0 {
+			s.line++
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			if decodeErr := s.decode(b); decodeErr != nil {
+				s.err = &LineError{Line: s.line, Err: decodeErr}
+				s.Close()
+				return false
+			}
+			return true
+		}
+		if err == io.EOF {
+			s.Close()
+			return false
+		}
+		if err != nil {
+			s.err = bodyError(err)
+			s.Close()
+			return false
+		}
+	}
+}
+
+func (s *Stream[T]) decode(b []byte) error {
+	var v T
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if s.useNumber {
+		dec.UseNumber()
+	}
+	if s.strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	if err := validateNested(s.r, reflect.ValueOf(&v).Elem(), "", nil); err != nil {
+		return err
+	}
+	s.value = v
+	return nil
+}
+
+// Value returns the value decoded by the last call to Next
+func (s *Stream[T]) Value() T {
+	return s.value
+}
+
+// Err returns the first error reading the stream, a *LineError for lines that fail to decode
+func (s *Stream[T]) Err() error {
+	return s.err
+}
+
+// All returns an iterator over the remaining values in the stream, stopping at the first error
+func (s *Stream[T]) All() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for s.Next() {
+			if !yield(s.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes the request body, and is safe to call once the stream is read
+func (s *Stream[T]) Close() error {
+	if s.close != nil {
+		s.close()
+		s.close = nil
+	}
+	return nil
+}
+
+func (s *Stream[T]) open(r *http.Request, reader *bufio.Reader, closeBody func(), useNumber, strict bool) {
+	*s = Stream[T]{r: r, reader: reader, close: closeBody, useNumber: useNumber, strict: strict}
+}
+
+// streamer is implemented by body fields that decode the request body once the request is decoded, such as Stream
+type streamer interface {
+	open(r *http.Request, reader *bufio.Reader, closeBody func(), useNumber, strict bool)
+}
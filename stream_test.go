@@ -0,0 +1,107 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	type event struct {
+		ID    int    `json:"id" min:"1"`
+		State string `json:"state"`
+	}
+	tests := []struct {
+		name     string
+		decoder  *Decoder
+		body     string
+		want     []event
+		wantLine int
+	}{
+		{
+			name:    "lines",
+			decoder: NewDecoder(),
+			body:    "{\"id\":1,\"state\":\"idle\"}\n\n{\"id\":2,\"state\":\"active\"}",
+			want:    []event{{ID: 1, State: "idle"}, {ID: 2, State: "active"}},
+		},
+		{
+			name:    "empty",
+			decoder: NewDecoder(),
+			body:    "",
+		},
+		{
+			name:     "invalid json",
+			decoder:  NewDecoder(),
+			body:     "{\"id\":1}\n{\"id\":2\n{\"id\":3}\n",
+			want:     []event{{ID: 1}},
+			wantLine: 2,
+		},
+		{
+			name:     "invalid value",
+			decoder:  NewDecoder(),
+			body:     "{\"id\":1}\n\n{\"id\":0}\n",
+			want:     []event{{ID: 1}},
+			wantLine: 3,
+		},
+		{
+			name:     "strict",
+			decoder:  NewDecoder(WithStrict()),
+			body:     "{\"id\":1,\"stat\":\"idle\"}\n",
+			wantLine: 1,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/x-ndjson")
+			var req struct {
+				Events Stream[event] `body:"application/x-ndjson"`
+			}
+			if err := tt.decoder.Decode(r, &req); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			defer req.Events.Close()
+
+			var got []event
+			req.Events.All()(func(e event) bool {
+				got = append(got, e)
+				return true
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stream.All() = %v, want %v", got, tt.want)
+			}
+
+			var lineErr *LineError
+			if tt.wantLine == 0 {
+				if err := req.Events.Err(); err != nil {
+					t.Errorf("Stream.Err() = %v, want nil", err)
+				}
+			} else if !errors.As(req.Events.Err(), &lineErr) || lineErr.Line != tt.wantLine {
+				t.Errorf("Stream.Err() = %v, want line %d error", req.Events.Err(), tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestStream_stop(t *testing.T) {
+	body := &trackingBody{Reader: strings.NewReader("{\"id\":1}\n{\"id\":2}\n")}
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	var events Stream[map[string]int]
+	if err := Decode(r, &events); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	events.All()(func(map[string]int) bool { return false })
+	if body.closed {
+		t.Errorf("Stream.All() closed body, want open until Close")
+	}
+	if !events.Next() || events.Value()["id"] != 2 {
+		t.Errorf("Stream.Next() value = %v, want id 2", events.Value())
+	}
+	if events.Next() || !body.closed {
+		t.Errorf("Stream.Next() closed %t, want closed once read", body.closed)
+	}
+}
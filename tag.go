@@ -0,0 +1,111 @@
+package request
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// tagOptions is the string following the lookup name in a struct tag
+type tagOptions string
+
+// parseTag splits a struct tag into its lookup name and comma separated options
+func parseTag(tag string) (string, tagOptions) {
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, tagOptions(opts)
+}
+
+// Contains reports whether the comma separated options include the option
+func (o tagOptions) Contains(option string) bool {
+	s := string(o)
+	for s != "" {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// structTags caches the parsed tags of struct types
+var structTags sync.Map
+
+// structInfo holds the parsed tags of the fields of a struct type
+type structInfo struct {
+	fields []fieldInfo
+	// taggedBody reports whether a field, or a field of a nested struct, is assigned from the request body
+	taggedBody bool
+	// rawBody reports whether a field, or a field of a nested struct, is assigned the raw request body
+	rawBody bool
+}
+
+// fieldInfo holds the parsed tags of a struct field
+type fieldInfo struct {
+	// sources are the source tags on the field, in order of precedence
+	sources []sourceTag
+	// rules are the validation tags on the field
+	rules      []ruleTag
+	defaultTag string
+	hasDefault bool
+	// trusted reports whether the field has a tag the request body must not assign
+	trusted bool
+	// nested reports whether the field is a struct decoded by its own tags
+	nested bool
+}
+
+type sourceTag struct {
+	source string
+	name   string
+	opts   tagOptions
+}
+
+type ruleTag struct {
+	rule string
+	tag  string
+}
+
+// typeInfo returns the parsed tags of the struct type, parsing them once per type
+func typeInfo(t reflect.Type) *structInfo {
+	if info, ok := structTags.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	info := &structInfo{fields: make([]fieldInfo, t.NumField())}
+	for i := range info.fields {
+		typ := t.Field(i)
+		field := &info.fields[i]
+		for _, source := range sourceTags {
+			if tag := typ.Tag.Get(source); tag != "" {
+				name, opts := parseTag(tag)
+				field.sources = append(field.sources, sourceTag{source: source, name: name, opts: opts})
+			}
+		}
+		for _, rule := range validationTags {
+			if tag, ok := typ.Tag.Lookup(rule); ok {
+				field.rules = append(field.rules, ruleTag{rule: rule, tag: tag})
+			}
+		}
+		field.defaultTag, field.hasDefault = typ.Tag.Lookup("default")
+		for _, tag := range trustedTags {
+			if _, ok := typ.Tag.Lookup(tag); ok {
+				field.trusted = true
+			}
+		}
+
+		body, ok := typ.Tag.Lookup("body")
+		info.taggedBody = info.taggedBody || ok
+		if name, _ := parseTag(body); name == "raw" {
+			info.rawBody = true
+		}
+		if typ.Type.Kind() == reflect.Struct {
+			nested := typeInfo(typ.Type)
+			info.taggedBody = info.taggedBody || nested.taggedBody
+			info.rawBody = info.rawBody || nested.rawBody
+			field.nested = !reflect.PointerTo(typ.Type).Implements(wrapperType)
+		}
+	}
+
+	stored, _ := structTags.LoadOrStore(t, info)
+	return stored.(*structInfo)
+}
@@ -0,0 +1,73 @@
+package request
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		wantName string
+		option   string
+		want     bool
+	}{
+		{name: "name only", tag: "id", wantName: "id", option: "required", want: false},
+		{name: "option", tag: "id,required", wantName: "id", option: "required", want: true},
+		{name: "multiple options", tag: "id,explode,required", wantName: "id", option: "required", want: true},
+		{name: "option prefix", tag: "id,requiredish", wantName: "id", option: "required", want: false},
+		{name: "empty name", tag: ",required", wantName: "", option: "required", want: true},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			name, opts := parseTag(tt.tag)
+			if name != tt.wantName {
+				t.Errorf("parseTag() name = %v, want %v", name, tt.wantName)
+			}
+			if got := opts.Contains(tt.option); got != tt.want {
+				t.Errorf("tagOptions.Contains(%q) = %v, want %v", tt.option, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_typeInfo(t *testing.T) {
+	type nested struct {
+		Raw []byte `body:"raw"`
+	}
+	type request struct {
+		ID     string        `query:"id,required" path:"id" minLen:"1" maxLen:"8"`
+		Limit  int           `query:"limit" default:"20"`
+		Tenant string        `ctx:"tenant"`
+		Page   Optional[int] `query:"page"`
+		Nested nested
+	}
+	info := typeInfo(reflect.TypeOf(request{}))
+	if info != typeInfo(reflect.TypeOf(request{})) {
+		t.Error("typeInfo() parsed the type again, want cached")
+	}
+	if !info.taggedBody || !info.rawBody {
+		t.Errorf("typeInfo() taggedBody = %v rawBody = %v, want nested body", info.taggedBody, info.rawBody)
+	}
+
+	id := info.fields[0]
+	wantSources := []sourceTag{{source: "query", name: "id", opts: "required"}, {source: "path", name: "id"}}
+	if !reflect.DeepEqual(id.sources, wantSources) {
+		t.Errorf("typeInfo() sources = %v, want %v", id.sources, wantSources)
+	}
+	wantRules := []ruleTag{{rule: "minLen", tag: "1"}, {rule: "maxLen", tag: "8"}}
+	if !reflect.DeepEqual(id.rules, wantRules) {
+		t.Errorf("typeInfo() rules = %v, want %v", id.rules, wantRules)
+	}
+	if limit := info.fields[1]; !limit.hasDefault || limit.defaultTag != "20" {
+		t.Errorf("typeInfo() default = %q, want 20", limit.defaultTag)
+	}
+	if !info.fields[2].trusted || info.fields[0].trusted {
+		t.Error("typeInfo() trusted, want only ctx fields")
+	}
+	if info.fields[3].nested || !info.fields[4].nested {
+		t.Error("typeInfo() nested, want structs other than wrappers")
+	}
+}
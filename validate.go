@@ -0,0 +1,454 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// validationTags are the struct tags that constrain decoded field values
+var validationTags = []string{"min", "max", "minLen", "maxLen", "minItems", "maxItems", "pattern", "enum"}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// patterns caches compiled pattern tags
+var patterns sync.Map
+
+// Normalizer is implemented by request types that adjust their decoded values before they are validated
+type Normalizer interface {
+	Normalize()
+}
+
+// Validator is implemented by request types that check their decoded values
+type Validator interface {
+	Validate() error
+}
+
+// AfterDecoder is implemented by request types that complete decoding once their values are validated
+type AfterDecoder interface {
+	AfterDecode(r *http.Request) error
+}
+
+// validateStruct checks the decoded struct value and the structs it holds.
+// Nested structs are checked first, then the struct is normalized, validated against the validation tags on its fields,
+// and validated and completed by its own hooks.
+// Validation tags are not checked on absent fields, which the request did not supply,
+// and structs with no validation tags or hooks are skipped.
+func validateStruct(r *http.Request, v reflect.Value, path string, absent absentFields) error {
+	if !needsValidation(v.Type()) {
+		return nil
+	}
+	return validateEmbedded(r, v, nil, path, absent)
+}
+
+// validateEmbedded checks a struct value embedded in the parent struct type, or not embedded when the parent is nil.
+// Hooks are promoted the same as other methods, so the struct skips the hooks in the method set of its parent,
+// which calls them once, or calls its own hook instead when it shadows them. The fields of embedded structs are
+// promoted too, and are checked once the struct is normalized.
+func validateEmbedded(r *http.Request, v reflect.Value, parent reflect.Type, path string, absent absentFields) error {
+	t := v.Type()
+	var embedded []int
+	for i := 0; i < t.NumField(); i++ {
+		typ := t.Field(i)
+		if !typ.IsExported() && !typ.Anonymous {
+			continue
+		}
+		if typ.Anonymous && embeddedStruct(v.Field(i)).IsValid() {
+			embedded = append(embedded, i)
+			continue
+		}
+		if err := validateNested(r, v.Field(i), fieldPath(path, typ.Name), absent); err != nil {
+			return err
+		}
+	}
+
+	target := hookTarget(v)
+	if normalizer, ok := target.(Normalizer); ok && !promoted(parent, normalizerType) {
+		normalizer.Normalize()
+	}
+	for _, i := range embedded {
+		if err := validateEmbedded(r, embeddedStruct(v.Field(i)), t, fieldPath(path, t.Field(i).Name), absent); err != nil {
+			return err
+		}
+	}
+	if err := validateFields(v, path, absent); err != nil {
+		return err
+	}
+	if validator, ok := target.(Validator); ok && !promoted(parent, validatorType) {
+		if err := validator.Validate(); err != nil {
+			return err
+		}
+	}
+	if afterDecoder, ok := target.(AfterDecoder); ok && !promoted(parent, afterDecoderType) {
+		if err := afterDecoder.AfterDecode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embeddedStruct returns the struct value of an embedded field through pointers,
+// or the zero Value when the field does not hold a struct to validate
+func embeddedStruct(field reflect.Value) reflect.Value {
+	for field.Kind() == reflect.Pointer && !field.IsNil() {
+		field = field.Elem()
+	}
+	if _, ok := asWrapper(field); ok || field.Kind() != reflect.Struct || field.Type() == timeType || !needsValidation(field.Type()) {
+		return reflect.Value{}
+	}
+	return field
+}
+
+// promoted reports whether the hook is in the method set of the parent struct type
+func promoted(parent, hook reflect.Type) bool {
+	return parent != nil && reflect.PointerTo(parent).Implements(hook)
+}
+
+// validateNested validates struct values held by the field, including through pointers and slices.
+// Values held by interfaces are skipped, discriminated values are validated as they are decoded.
+func validateNested(r *http.Request, v reflect.Value, path string, absent absentFields) error {
+	if !needsValidation(v.Type()) {
+		return nil
+	}
+	if w, ok := asWrapper(v); ok {
+		value, set := w.wrapped()
+		if !set {
+			return nil
+		}
+		return validateNested(r, value, path, absent)
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return validateNested(r, v.Elem(), path, absent)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateNested(r, v.Index(i), fmt.Sprintf("%s[%d]", path, i), absent); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return nil
+		}
+		return validateStruct(r, v, path, absent)
+	}
+	return nil
+}
+
+var (
+	normalizerType   = reflect.TypeOf((*Normalizer)(nil)).Elem()
+	validatorType    = reflect.TypeOf((*Validator)(nil)).Elem()
+	afterDecoderType = reflect.TypeOf((*AfterDecoder)(nil)).Elem()
+)
+
+// validatedTypes caches whether values of a type need to be validated
+var validatedTypes sync.Map
+
+// needsValidation reports whether values of the type may hold validation tags or hooks
+func needsValidation(t reflect.Type) bool {
+	if needs, ok := validatedTypes.Load(t); ok {
+		return needs.(bool)
+	}
+	needs := typeNeedsValidation(t, map[reflect.Type]bool{})
+	validatedTypes.Store(t, needs)
+	return needs
+}
+
+// typeNeedsValidation checks the type and the types it holds, skipping types already being checked by recursive types
+func typeNeedsValidation(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if needs, ok := validatedTypes.Load(t); ok {
+		return needs.(bool)
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return typeNeedsValidation(t.Elem(), visiting)
+	case reflect.Struct:
+		if t == timeType {
+			return false
+		}
+		if reflect.PointerTo(t).Implements(wrapperType) {
+			return typeNeedsValidation(jsonValueType(t), visiting)
+		}
+		for _, hook := range []reflect.Type{normalizerType, validatorType, afterDecoderType} {
+			if reflect.PointerTo(t).Implements(hook) {
+				return true
+			}
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			for _, rule := range validationTags {
+				if _, ok := f.Tag.Lookup(rule); ok {
+					return true
+				}
+			}
+			if typeNeedsValidation(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hookTarget returns the value to check for hook implementations, preferring a pointer to the value.
+// Values of unexported embedded structs cannot be used, and have no hooks.
+func hookTarget(v reflect.Value) interface{} {
+	if v.CanAddr() && v.Addr().CanInterface() {
+		return v.Addr().Interface()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// fieldKey identifies a struct field value by its address and type
+type fieldKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// absentFields are the fields with a source tag the request did not supply
+type absentFields map[fieldKey]bool
+
+func (a absentFields) add(v reflect.Value) {
+	if a != nil && v.CanAddr() {
+		a[fieldKey{addr: v.Addr().Pointer(), typ: v.Type()}] = true
+	}
+}
+
+func (a absentFields) has(v reflect.Value) bool {
+	return v.CanAddr() && a[fieldKey{addr: v.Addr().Pointer(), typ: v.Type()}]
+}
+
+// validateFields checks the fields of the struct value against their validation tags.
+// Absent fields are skipped unless they were assigned from the request body.
+func validateFields(v reflect.Value, path string, absent absentFields) error {
+	t := v.Type()
+	for i, info := range typeInfo(t).fields {
+		if len(info.rules) == 0 {
+			continue
+		}
+		typ := t.Field(i)
+		if !typ.IsExported() {
+			continue
+		}
+		field := v.Field(i)
+		if absent.has(field) && field.IsZero() {
+			continue
+		}
+		name := fieldPath(path, typ.Name)
+
+		for _, rule := range info.rules {
+			index, violation, err := validateRule(field, rule.rule, rule.tag)
+			if err != nil {
+				return fmt.Errorf("invalid %s tag on field %s: %w", rule.rule, name, err)
+			}
+			if violation != "" {
+				var source, key string
+				if len(info.sources) > 0 {
+					source, key = info.sources[0].source, info.sources[0].name
+				}
+				if index >= 0 {
+					name = fmt.Sprintf("%s[%d]", name, index)
+				}
+				return &FieldError{Field: name, Source: source, Name: key, Rule: rule.rule, Err: fmt.Errorf("must %s", violation)}
+			}
+		}
+	}
+	return nil
+}
+
+// validateRule checks the value against a validation rule, returning the violated constraint.
+// Rules other than minItems and maxItems are checked against each item of a slice, returning the index of the failing item.
+func validateRule(v reflect.Value, rule, tag string) (int, string, error) {
+	if w, ok := asWrapper(v); ok {
+		value, set := w.wrapped()
+		if !set {
+			return -1, "", nil
+		}
+		return validateRule(value, rule, tag)
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return -1, "", nil
+		}
+		return validateRule(v.Elem(), rule, tag)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if rule == "minItems" || rule == "maxItems" {
+			violation, err := validateItems(v, rule, tag)
+			return -1, violation, err
+		}
+		if v.Kind() == reflect.Map {
+			return -1, "", fmt.Errorf("unsupported type: %v", v.Type())
+		}
+		for i := 0; i < v.Len(); i++ {
+			_, violation, err := validateRule(v.Index(i), rule, tag)
+			if err != nil || violation != "" {
+				return i, violation, err
+			}
+		}
+		return -1, "", nil
+	}
+
+	var violation string
+	var err error
+	switch rule {
+	case "min", "max":
+		violation, err = validateBound(v, rule, tag)
+	case "minLen", "maxLen":
+		violation, err = validateLength(v, rule, tag)
+	case "pattern":
+		violation, err = validatePattern(v, tag)
+	case "enum":
+		violation, err = validateEnum(v, tag)
+	default:
+		err = fmt.Errorf("unsupported type: %v", v.Type())
+	}
+	return -1, violation, err
+}
+
+// validateBound checks a number or duration against a min or max rule
+func validateBound(v reflect.Value, rule, tag string) (string, error) {
+	var cmp int
+	switch {
+	case v.Type() == durationType:
+		bound, err := time.ParseDuration(tag)
+		if err != nil {
+			return "", err
+		}
+		cmp = compare(v.Int(), int64(bound))
+	case v.CanInt():
+		bound, err := strconv.ParseInt(tag, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		cmp = compare(v.Int(), bound)
+	case v.CanUint():
+		bound, err := strconv.ParseUint(tag, 10, 64)
+		if err != nil {
+			return "", err
+		}
+		cmp = compare(v.Uint(), bound)
+	case v.CanFloat():
+		bound, err := strconv.ParseFloat(tag, 64)
+		if err != nil {
+			return "", err
+		}
+		cmp = compare(v.Float(), bound)
+	default:
+		return "", fmt.Errorf("unsupported type: %v", v.Type())
+	}
+
+	if rule == "min" && cmp < 0 {
+		return "be at least " + tag, nil
+	}
+	if rule == "max" && cmp > 0 {
+		return "be at most " + tag, nil
+	}
+	return "", nil
+}
+
+// validateLength checks the character length of a string against a minLen or maxLen rule
+func validateLength(v reflect.Value, rule, tag string) (string, error) {
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("unsupported type: %v", v.Type())
+	}
+	bound, err := strconv.Atoi(tag)
+	if err != nil {
+		return "", err
+	}
+	length := utf8.RuneCountInString(v.String())
+	if rule == "minLen" && length < bound {
+		return fmt.Sprintf("be at least %d characters", bound), nil
+	}
+	if rule == "maxLen" && length > bound {
+		return fmt.Sprintf("be at most %d characters", bound), nil
+	}
+	return "", nil
+}
+
+// validateItems checks the length of a slice or map against a minItems or maxItems rule
+func validateItems(v reflect.Value, rule, tag string) (string, error) {
+	bound, err := strconv.Atoi(tag)
+	if err != nil {
+		return "", err
+	}
+	if rule == "minItems" && v.Len() < bound {
+		return fmt.Sprintf("have at least %d items", bound), nil
+	}
+	if rule == "maxItems" && v.Len() > bound {
+		return fmt.Sprintf("have at most %d items", bound), nil
+	}
+	return "", nil
+}
+
+// validatePattern checks a string against a regular expression
+func validatePattern(v reflect.Value, tag string) (string, error) {
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("unsupported type: %v", v.Type())
+	}
+	re, ok := patterns.Load(tag)
+	if !ok {
+		compiled, err := regexp.Compile(tag)
+		if err != nil {
+			return "", err
+		}
+		re, _ = patterns.LoadOrStore(tag, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(v.String()) {
+		return fmt.Sprintf("match pattern %q", tag), nil
+	}
+	return "", nil
+}
+
+// validateEnum checks the value is one of the comma separated allowed values
+func validateEnum(v reflect.Value, tag string) (string, error) {
+	for _, option := range strings.Split(tag, ",") {
+		if v.Kind() == reflect.String {
+			if v.String() == option {
+				return "", nil
+			}
+			continue
+		}
+		allowed, err := resolve(reflect.Zero(v.Type()).Interface(), option)
+		if err != nil {
+			return "", err
+		}
+		if reflect.DeepEqual(v.Interface(), allowed) {
+			return "", nil
+		}
+	}
+	return "be one of " + tag, nil
+}
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
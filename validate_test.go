@@ -0,0 +1,410 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_validateStruct(t *testing.T) {
+	limit := 500
+	tests := []struct {
+		name      string
+		input     interface{}
+		wantField string
+		wantRule  string
+		wantErr   bool
+	}{
+		{
+			name: "valid",
+			input: struct {
+				Limit   int           `query:"limit" min:"1" max:"100"`
+				Ratio   float64       `min:"0.5"`
+				Count   uint          `max:"10"`
+				Timeout time.Duration `min:"1s" max:"1m"`
+				Name    string        `minLen:"2" maxLen:"5"`
+				Tags    []string      `minItems:"1" maxItems:"3" pattern:"^[a-z]+$"`
+				Sort    string        `enum:"asc,desc"`
+				Level   int           `enum:"1,2,3"`
+				Nil     *int          `min:"1"`
+			}{Limit: 50, Ratio: 0.5, Count: 10, Timeout: time.Second, Name: "héllo", Tags: []string{"a", "b"}, Sort: "asc", Level: 2},
+		},
+		{
+			name: "min",
+			input: struct {
+				Limit int `query:"limit" min:"1"`
+			}{},
+			wantField: "Limit",
+			wantRule:  "min",
+		},
+		{
+			name: "max pointer",
+			input: struct {
+				Limit *int `query:"limit" max:"100"`
+			}{Limit: &limit},
+			wantField: "Limit",
+			wantRule:  "max",
+		},
+		{
+			name: "max duration",
+			input: struct {
+				Timeout time.Duration `header:"X-Timeout" max:"1m"`
+			}{Timeout: time.Hour},
+			wantField: "Timeout",
+			wantRule:  "max",
+		},
+		{
+			name: "minLen",
+			input: struct {
+				Name string `minLen:"2"`
+			}{Name: "a"},
+			wantField: "Name",
+			wantRule:  "minLen",
+		},
+		{
+			name: "maxItems",
+			input: struct {
+				Tags []string `maxItems:"1"`
+			}{Tags: []string{"a", "b"}},
+			wantField: "Tags",
+			wantRule:  "maxItems",
+		},
+		{
+			name: "pattern item",
+			input: struct {
+				Tags []string `pattern:"^[a-z]+$"`
+			}{Tags: []string{"a", "B"}},
+			wantField: "Tags[1]",
+			wantRule:  "pattern",
+		},
+		{
+			name: "enum",
+			input: struct {
+				Sort string `enum:"asc,desc"`
+			}{Sort: "up"},
+			wantField: "Sort",
+			wantRule:  "enum",
+		},
+		{
+			name: "nested",
+			input: struct {
+				Items []struct {
+					Count int `max:"5"`
+				}
+			}{Items: []struct {
+				Count int `max:"5"`
+			}{{Count: 1}, {Count: 6}}},
+			wantField: "Items[1].Count",
+			wantRule:  "max",
+		},
+		{
+			name: "invalid tag",
+			input: struct {
+				Limit int `min:"one"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "unsupported type",
+			input: struct {
+				Limit int `pattern:".*"`
+			}{},
+			wantErr: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStruct(nil, reflect.ValueOf(tt.input), "", nil)
+			var fieldErr *FieldError
+			switch {
+			case tt.wantErr:
+				if err == nil || errors.As(err, &fieldErr) {
+					t.Errorf("validateStruct() error = %v, want tag error", err)
+				}
+			case tt.wantField == "":
+				if err != nil {
+					t.Errorf("validateStruct() error = %v, want nil", err)
+				}
+			default:
+				if !errors.As(err, &fieldErr) {
+					t.Fatalf("validateStruct() error = %v, want field error", err)
+				}
+				if fieldErr.Field != tt.wantField || fieldErr.Rule != tt.wantRule {
+					t.Errorf("validateStruct() field = %v rule = %v, want %v %v", fieldErr.Field, fieldErr.Rule, tt.wantField, tt.wantRule)
+				}
+			}
+		})
+	}
+}
+
+type hookRange struct {
+	Start int `min:"0"`
+	End   int
+	calls []string
+}
+
+func (h *hookRange) Normalize() {
+	h.calls = append(h.calls, "Normalize")
+	if h.Start < 0 {
+		h.Start = 0
+	}
+}
+
+func (h *hookRange) Validate() error {
+	h.calls = append(h.calls, "Validate")
+	if h.End < h.Start {
+		return errors.New("end must be after start")
+	}
+	return nil
+}
+
+func (h *hookRange) AfterDecode(r *http.Request) error {
+	h.calls = append(h.calls, "AfterDecode")
+	if r.Header.Get("X-Fail") != "" {
+		return errors.New("after decode failed")
+	}
+	return nil
+}
+
+type hookRequest struct {
+	hookRange
+	Nested  hookRange
+	Pointer *hookRange
+	Items   []hookRange
+}
+
+func Test_validateStruct_hooks(t *testing.T) {
+	want := []string{"Normalize", "Validate", "AfterDecode"}
+	tests := []struct {
+		name    string
+		header  string
+		input   hookRequest
+		wantErr bool
+	}{
+		{
+			name:  "hooks",
+			input: hookRequest{hookRange: hookRange{Start: -1, End: 1}, Pointer: &hookRange{}, Items: []hookRange{{}, {}}},
+		},
+		{
+			name:    "validate failure",
+			input:   hookRequest{Nested: hookRange{Start: 2, End: 1}},
+			wantErr: true,
+		},
+		{
+			name:    "after decode failure",
+			header:  "true",
+			input:   hookRequest{},
+			wantErr: true,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("X-Fail", tt.header)
+			err := validateStruct(r, reflect.ValueOf(&tt.input).Elem(), "", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.input.Start != 0 {
+				t.Errorf("validateStruct() Start = %v, want normalized 0", tt.input.Start)
+			}
+			for _, h := range append([]hookRange{tt.input.hookRange, tt.input.Nested, *tt.input.Pointer}, tt.input.Items...) {
+				if !reflect.DeepEqual(h.calls, want) {
+					t.Errorf("validateStruct() calls = %v, want %v", h.calls, want)
+				}
+			}
+		})
+	}
+}
+
+type hookShadow struct {
+	hookRange
+	validated bool
+}
+
+func (h *hookShadow) Validate() error {
+	h.validated = true
+	return nil
+}
+
+type HookCheck struct {
+	checked bool
+}
+
+func (h *HookCheck) Validate() error {
+	h.checked = true
+	return nil
+}
+
+type HookVerify struct {
+	verified bool
+}
+
+func (h *HookVerify) Validate() error {
+	h.verified = true
+	return nil
+}
+
+type hookAmbiguous struct {
+	HookCheck
+	HookVerify
+}
+
+type hookPromoted struct {
+	*HookCheck
+}
+
+func Test_validateStruct_embeddedHooks(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	t.Run("shadowed", func(t *testing.T) {
+		input := hookShadow{hookRange: hookRange{Start: 2, End: 1}}
+		if err := validateStruct(r, reflect.ValueOf(&input).Elem(), "", nil); err != nil {
+			t.Fatalf("validateStruct() unexpected error: %v", err)
+		}
+		if want := []string{"Normalize", "AfterDecode"}; !reflect.DeepEqual(input.calls, want) {
+			t.Errorf("validateStruct() calls = %v, want %v", input.calls, want)
+		}
+		if !input.validated {
+			t.Error("validateStruct() shadowing Validate not called")
+		}
+	})
+
+	t.Run("promoted pointer", func(t *testing.T) {
+		input := hookPromoted{HookCheck: &HookCheck{}}
+		if err := validateStruct(r, reflect.ValueOf(&input).Elem(), "", nil); err != nil {
+			t.Fatalf("validateStruct() unexpected error: %v", err)
+		}
+		if !input.checked {
+			t.Error("validateStruct() promoted Validate not called")
+		}
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		input := hookAmbiguous{}
+		if err := validateStruct(r, reflect.ValueOf(&input).Elem(), "", nil); err != nil {
+			t.Fatalf("validateStruct() unexpected error: %v", err)
+		}
+		if !input.checked || !input.verified {
+			t.Errorf("validateStruct() checked = %v verified = %v, want both embedded hooks", input.checked, input.verified)
+		}
+	})
+}
+
+func TestDecode_absentValidation(t *testing.T) {
+	type request struct {
+		Sort   string  `query:"sort" enum:"asc,desc"`
+		Cursor string  `query:"cursor" pattern:"^[a-z0-9]+$"`
+		Page   int     `query:"page" min:"1"`
+		Limit  int     `query:"limit" default:"0" min:"1"`
+		Tenant *string `header:"X-Tenant" minLen:"2"`
+		Name   string  `query:"name" json:"name" minLen:"1"`
+	}
+	tests := []struct {
+		name      string
+		target    string
+		body      string
+		wantField string
+		wantRule  string
+	}{
+		{
+			name:   "absent",
+			target: "/?limit=5",
+		},
+		{
+			name:      "supplied enum",
+			target:    "/?limit=5&sort=up",
+			wantField: "Sort",
+			wantRule:  "enum",
+		},
+		{
+			name:      "supplied pattern",
+			target:    "/?limit=5&cursor=A!",
+			wantField: "Cursor",
+			wantRule:  "pattern",
+		},
+		{
+			name:      "supplied empty",
+			target:    "/?limit=5&sort=",
+			wantField: "Sort",
+			wantRule:  "enum",
+		},
+		{
+			name:      "default",
+			target:    "/",
+			wantField: "Limit",
+			wantRule:  "min",
+		},
+		{
+			name:   "supplied by body",
+			target: "/?limit=5",
+			body:   `{"name":"adam"}`,
+		},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			var req request
+			err := Decode(r, &req)
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Errorf("Decode() unexpected error: %v", err)
+				}
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != tt.wantField || fieldErr.Rule != tt.wantRule {
+				t.Errorf("Decode() error = %v, want %s %s", err, tt.wantField, tt.wantRule)
+			}
+		})
+	}
+}
+
+type validateNode struct {
+	Parent   *validateParent
+	Children []validateNode
+}
+
+type validateParent struct {
+	Node  *validateNode
+	Count int `max:"1"`
+}
+
+func Test_needsValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  bool
+	}{
+		{name: "scalar", input: 0, want: false},
+		{name: "time", input: time.Time{}, want: false},
+		{name: "untagged", input: struct{ Name string }{}, want: false},
+		{name: "tagged", input: struct {
+			Name string `maxLen:"1"`
+		}{}, want: true},
+		{name: "hooks", input: []*hookRange{}, want: true},
+		{name: "interface", input: struct{ Any interface{} }{}, want: false},
+		{name: "optional", input: struct{ Range Optional[hookRange] }{}, want: true},
+		{name: "recursive", input: validateNode{}, want: true},
+		{name: "recursive parent", input: validateParent{}, want: true},
+	}
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsValidation(reflect.TypeOf(tt.input)); got != tt.want {
+				t.Errorf("needsValidation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}